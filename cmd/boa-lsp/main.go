@@ -0,0 +1,70 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+// Command boa-lsp is a language server for configuration files written in
+// any of the languages supported by boa. It speaks the Language Server
+// Protocol over its standard input and output.
+//
+// Usage:
+//
+//	boa-lsp [-schema pattern=schema.json]...
+//
+// Each -schema flag associates a JSON Schema with the configuration files
+// whose name matches the pattern (e.g. "myapp.*"), which enables key
+// completion, hover documentation and validation for these files.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"snai.pe/boa/lsp"
+)
+
+type schemaFlag []string
+
+func (f *schemaFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *schemaFlag) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("%q is not in pattern=path form", value)
+	}
+	*f = append(*f, value)
+	return nil
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("boa-lsp: ")
+
+	var schemas schemaFlag
+	flag.Var(&schemas, "schema", "associate the JSON Schema at `pattern=path` with matching files; may be repeated")
+	flag.Bool("stdio", true, "communicate over stdin and stdout (always enabled)")
+	flag.Parse()
+
+	server := lsp.NewServer()
+	for _, s := range schemas {
+		split := strings.SplitN(s, "=", 2)
+		f, err := os.Open(split[1])
+		if err != nil {
+			log.Fatalln(err)
+		}
+		schema, err := lsp.LoadSchema(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("%s: %v", split[1], err)
+		}
+		server.RegisterSchema(split[0], schema)
+	}
+
+	if err := server.Serve(os.Stdin, os.Stdout); err != nil {
+		log.Fatalln(err)
+	}
+}
@@ -0,0 +1,356 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/constant"
+	"go/token"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"snai.pe/boa"
+	"snai.pe/boa/syntax"
+)
+
+const diffUsage = `usage: boa diff <a> <b>
+
+Diff prints the semantic differences between two configuration files, by
+key path. Formatting, comments, key order, and the configuration language
+of either file are ignored; numbers are compared by value.
+
+Each difference is printed on its own line, prefixed with "-" for values
+only present in <a>, "+" for values only present in <b>, and "~" for
+values that changed.
+
+The exit status is 0 if the files are equivalent, 1 if they differ, and 2
+if an error occured.
+`
+
+func runDiff(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { fmt.Fprint(stderr, diffUsage) }
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return 2
+	}
+
+	var docs [2]interface{}
+	for i, name := range flags.Args() {
+		v, err := loadDocument(name)
+		if err != nil {
+			fmt.Fprintf(stderr, "boa diff: %v\n", err)
+			return 2
+		}
+		docs[i] = v
+	}
+
+	d := differ{out: stdout}
+	d.diff(nil, docs[0], docs[1])
+	if d.changed {
+		return 1
+	}
+	return 0
+}
+
+// loadDocument parses the specified file and returns its contents as a
+// format-independent tree of map[string]interface{}, []interface{}, and
+// scalar values.
+func loadDocument(name string) (interface{}, error) {
+	ext := filepath.Ext(name)
+	newDecoder, ok := boa.Decoders[ext]
+	if !ok {
+		return nil, fmt.Errorf("%s: no known decoder for file extension %q", name, ext)
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var doc *syntax.Document
+	if err := newDecoder(f).Decode(&doc); err != nil {
+		return nil, err
+	}
+	return normalize(doc.Root), nil
+}
+
+func normalize(node syntax.Value) interface{} {
+	switch n := node.(type) {
+	case nil:
+		return nil
+	case *syntax.Alias:
+		return normalize(n.Target)
+	case *syntax.Map:
+		out := map[string]interface{}{}
+		for _, entry := range n.Entries {
+			var at []interface{}
+			if kp, ok := entry.Key.(syntax.KeyPather); ok {
+				at = kp.KeyPathComponents()
+			} else {
+				at = []interface{}{formatKey(normalize(entry.Key))}
+			}
+			insert(out, at, normalize(entry.Value))
+		}
+		return out
+	case *syntax.List:
+		out := make([]interface{}, len(n.Items))
+		for i, item := range n.Items {
+			out[i] = normalize(item)
+		}
+		return out
+	case *syntax.String:
+		return n.Value
+	case *syntax.Number:
+		return n.Value
+	case *syntax.Bool:
+		return n.Value
+	case *syntax.Nil:
+		return nil
+	}
+
+	// Format-specific scalars (e.g. TOML dates) hold their value in a Value
+	// field.
+	if rv := reflect.ValueOf(node).Elem(); rv.Kind() == reflect.Struct {
+		if field := rv.FieldByName("Value"); field.IsValid() && field.CanInterface() {
+			return field.Interface()
+		}
+	}
+	return node
+}
+
+func formatKey(key interface{}) string {
+	switch k := key.(type) {
+	case string:
+		return k
+	case nil:
+		return "null"
+	default:
+		return formatScalar(k)
+	}
+}
+
+// insert sets the value at the specified key path in container, creating
+// intermediate maps and lists as needed, and returns the updated container.
+// Maps that are set more than once (e.g. TOML tables and dotted keys
+// sharing a prefix) are merged.
+func insert(container interface{}, at []interface{}, val interface{}) interface{} {
+	if len(at) == 0 {
+		old, ok1 := container.(map[string]interface{})
+		upd, ok2 := val.(map[string]interface{})
+		if ok1 && ok2 {
+			for k, v := range upd {
+				old[k] = insert(old[k], nil, v)
+			}
+			return old
+		}
+		return val
+	}
+	switch comp := at[0].(type) {
+	case int:
+		list, _ := container.([]interface{})
+		for len(list) <= comp {
+			list = append(list, nil)
+		}
+		list[comp] = insert(list[comp], at[1:], val)
+		return list
+	default:
+		m, ok := container.(map[string]interface{})
+		if !ok {
+			m = map[string]interface{}{}
+		}
+		key := fmt.Sprint(comp)
+		m[key] = insert(m[key], at[1:], val)
+		return m
+	}
+}
+
+type differ struct {
+	out     io.Writer
+	changed bool
+}
+
+func (d *differ) diff(path []interface{}, a, b interface{}) {
+	switch a := a.(type) {
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(a)+len(b))
+		for k := range a {
+			keys = append(keys, k)
+		}
+		for k := range b {
+			if _, ok := a[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			va, ina := a[k]
+			vb, inb := b[k]
+			switch {
+			case !inb:
+				d.print("-", append(path, k), va)
+			case !ina:
+				d.print("+", append(path, k), vb)
+			default:
+				d.diff(append(path, k), va, vb)
+			}
+		}
+		return
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(a) || i < len(b); i++ {
+			switch {
+			case i >= len(b):
+				d.print("-", append(path, i), a[i])
+			case i >= len(a):
+				d.print("+", append(path, i), b[i])
+			default:
+				d.diff(append(path, i), a[i], b[i])
+			}
+		}
+		return
+	default:
+		if isScalar(b) {
+			if !scalarEqual(a, b) {
+				d.changed = true
+				fmt.Fprintf(d.out, "~ %s: %s -> %s\n", formatPath(path), formatScalar(a), formatScalar(b))
+			}
+			return
+		}
+	}
+
+	// The values are of a different kind; report them as replaced.
+	d.print("-", path, a)
+	d.print("+", path, b)
+}
+
+// print reports every leaf value of v as either removed or added.
+func (d *differ) print(sign string, path []interface{}, v interface{}) {
+	d.changed = true
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			fmt.Fprintf(d.out, "%s %s: {}\n", sign, formatPath(path))
+			return
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			d.print(sign, append(path, k), v[k])
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			fmt.Fprintf(d.out, "%s %s: []\n", sign, formatPath(path))
+			return
+		}
+		for i, item := range v {
+			d.print(sign, append(path, i), item)
+		}
+	default:
+		fmt.Fprintf(d.out, "%s %s: %s\n", sign, formatPath(path), formatScalar(v))
+	}
+}
+
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		return false
+	}
+	return true
+}
+
+func scalarEqual(a, b interface{}) bool {
+	switch a := a.(type) {
+	case constant.Value:
+		b, ok := b.(constant.Value)
+		return ok && constant.Compare(a, token.EQL, b)
+	case float64:
+		b, ok := b.(float64)
+		return ok && (a == b || math.IsNaN(a) && math.IsNaN(b))
+	case time.Time:
+		b, ok := b.(time.Time)
+		return ok && a.Equal(b)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func formatScalar(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(v)
+	case constant.Value:
+		if v.Kind() == constant.Int {
+			return v.ExactString()
+		}
+		f, _ := constant.Float64Val(v)
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return "nan"
+		case math.IsInf(v, 1):
+			return "+inf"
+		case math.IsInf(v, -1):
+			return "-inf"
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func formatPath(path []interface{}) string {
+	if len(path) == 0 {
+		return "."
+	}
+	var out strings.Builder
+	for i, comp := range path {
+		switch comp := comp.(type) {
+		case int:
+			fmt.Fprintf(&out, "[%d]", comp)
+		default:
+			key := fmt.Sprint(comp)
+			if i > 0 {
+				out.WriteByte('.')
+			}
+			if bareKey.MatchString(key) {
+				out.WriteString(key)
+			} else {
+				out.WriteString(strconv.Quote(key))
+			}
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDiff(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.toml": `# The server configuration.
+name = "svc"
+tags = ["a", "b"]
+
+[server]
+port = 8080
+ratio = 0.5
+
+[[backends]]
+host = "10.0.0.1"
+
+[[backends]]
+host = "10.0.0.2"
+`,
+		"same.yaml": `server:
+  ratio: 0.50
+  port: 8080
+backends:
+  - host: 10.0.0.1
+  - {host: 10.0.0.2}
+tags: [a, b]
+name: svc
+`,
+		"other.json5": `{
+  name: "svc",
+  tags: ["a"],
+  server: {port: 8081, ratio: 0.5, "bind.addr": "::"},
+  backends: {},
+}
+`,
+	})
+
+	for _, tc := range []struct {
+		name   string
+		a, b   string
+		status int
+		out    string
+	}{
+		{"same", "a.toml", "same.yaml", 0, ""},
+		{"reflexive", "other.json5", "other.json5", 0, ""},
+		{"changed", "a.toml", "other.json5", 1, `- backends[0].host: "10.0.0.1"
+- backends[1].host: "10.0.0.2"
++ backends: {}
++ server."bind.addr": "::"
+~ server.port: 8080 -> 8081
+- tags[1]: "b"
+`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			status := runDiff([]string{filepath.Join(dir, tc.a), filepath.Join(dir, tc.b)}, nil, &stdout, &stderr)
+			if status != tc.status {
+				t.Errorf("expected exit status %d, got %d (stderr: %q)", tc.status, status, stderr.String())
+			}
+			if stdout.String() != tc.out {
+				t.Errorf("unexpected output:\n%s\nexpected:\n%s", stdout.String(), tc.out)
+			}
+		})
+	}
+}
+
+func TestDiffErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.toml":  "a = 1\n",
+		"bad.ini": "a = 1\n",
+	})
+
+	for _, args := range [][]string{
+		{filepath.Join(dir, "a.toml")},
+		{filepath.Join(dir, "a.toml"), filepath.Join(dir, "bad.ini")},
+		{filepath.Join(dir, "a.toml"), filepath.Join(dir, "missing.toml")},
+	} {
+		var stdout, stderr bytes.Buffer
+		if status := runDiff(args, nil, &stdout, &stderr); status != 2 {
+			t.Errorf("%v: expected exit status 2, got %d", args, status)
+		}
+		if stderr.Len() == 0 {
+			t.Errorf("%v: expected an error message", args)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"snai.pe/boa"
+	"snai.pe/boa/encoding"
+	"snai.pe/boa/internal/fsutil"
+	"snai.pe/boa/syntax"
+)
+
+const editUsage = `usage: boa edit [-ext .toml] <name>
+
+Edit opens the configuration file with the specified name in $VISUAL (or
+$EDITOR), and replaces it once the editor exits.
+
+The file is searched in the configuration paths returned by boa.ConfigPaths,
+and the most important file that exists is edited, unless the environment
+variable of the name (e.g. MYAPP_CONFIG for myapp) holds a file to edit. If there is none, a new file is
+created in the user configuration home, with the extension of the name or,
+if it has none, the one specified by -ext.
+
+The changes are made to a temporary copy of the file. When the editor exits,
+the copy is parsed; if it is invalid, the errors are shown and the editor
+is opened again until the file is valid, or the changes are abandoned. The
+original file is then atomically replaced with the copy.
+`
+
+func runEdit(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("edit", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { fmt.Fprint(stderr, editUsage) }
+	ext := flags.String("ext", ".toml", "extension of the file to create if none exist")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	fail := func(err error) int {
+		fmt.Fprintf(stderr, "boa edit: %v\n", err)
+		return 1
+	}
+
+	name := flags.Arg(0)
+	if filepath.IsAbs(name) || strings.HasPrefix(name, "."+string(filepath.Separator)) || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return fail(fmt.Errorf("%s: name must be relative to the configuration paths", name))
+	}
+
+	path, err := locate(name)
+	if err != nil {
+		return fail(err)
+	}
+	if path == "" {
+		home, err := boa.ConfigHome()
+		if err != nil {
+			return fail(err)
+		}
+		path = filepath.Join(home, name)
+		if filepath.Ext(name) == "" {
+			path += *ext
+		}
+	}
+
+	newDecoder, ok := boa.Decoders[filepath.Ext(path)]
+	if !ok {
+		return fail(fmt.Errorf("%s: no known decoder for file extension %q", path, filepath.Ext(path)))
+	}
+
+	orig, err := os.ReadFile(path)
+	mode := fs.FileMode(0644)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			return fail(err)
+		}
+	case err != nil:
+		return fail(err)
+	default:
+		info, err := os.Stat(path)
+		if err != nil {
+			return fail(err)
+		}
+		mode = info.Mode().Perm()
+	}
+
+	// The temporary copy is created next to the original file. It keeps the
+	// original extension so that editors can pick the right syntax
+	// highlighting.
+	base := filepath.Base(path)
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+strings.TrimSuffix(base, filepath.Ext(base))+".*"+filepath.Ext(base))
+	if err != nil {
+		return fail(err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	_, err = tmp.Write(orig)
+	if err == nil {
+		err = tmp.Chmod(mode)
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fail(err)
+	}
+
+	answers := bufio.NewReader(stdin)
+	var edited []byte
+	for {
+		if err := runEditor(tmpPath, stdin, stdout, stderr); err != nil {
+			return fail(fmt.Errorf("%v; changes to %s abandoned", err, path))
+		}
+
+		edited, err = os.ReadFile(tmpPath)
+		if err != nil {
+			return fail(err)
+		}
+		if bytes.Equal(edited, orig) {
+			fmt.Fprintf(stderr, "boa edit: %s unchanged\n", path)
+			return 0
+		}
+
+		err = validate(newDecoder, path, edited)
+		if err == nil {
+			break
+		}
+		fmt.Fprintln(stderr, renderError(err, edited))
+
+		fmt.Fprint(stderr, "What now? (e)dit again, (q)uit without saving [e]: ")
+		answer, err := answers.ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if err != nil && answer == "" || answer == "q" {
+			fmt.Fprintf(stderr, "boa edit: changes to %s abandoned\n", path)
+			return 1
+		}
+	}
+
+	// The original file is rewritten rather than replaced by the copy, so
+	// that it is synced to disk, and that symbolic links and ownership are
+	// preserved.
+	err = fsutil.WriteFile(path, 0, func(w io.Writer) error {
+		_, err := w.Write(edited)
+		return err
+	})
+	if err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+// locate returns the path of the most important existing configuration file
+// with the specified name, or the empty string if there are none. Like
+// boa.ConfigFilePaths, a file set in the environment variable of the name
+// takes precedence over the search paths.
+func locate(name string) (string, error) {
+	if file := os.Getenv(boa.ConfigFileEnv(name)); file == "-" {
+		return "", fmt.Errorf("%s: cannot edit the standard input", boa.ConfigFileEnv(name))
+	} else if file != "" {
+		return file, nil
+	}
+
+	exts := make([]string, 0, len(boa.Decoders))
+	for ext := range boa.Decoders {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+
+	files := boa.Open(name, boa.ConfigPaths()...)
+	defer files.Close()
+	for {
+		err := files.Next(exts...)
+		if errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	used := files.Used()
+	if len(used) == 0 {
+		return "", nil
+	}
+	path := filepath.FromSlash(used[len(used)-1])
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("%s: not a file on disk", used[len(used)-1])
+	}
+	return path, nil
+}
+
+func runEditor(path string, stdin io.Reader, stdout, stderr io.Writer) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	args := strings.Fields(editor)
+
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	if f, ok := stdin.(*os.File); ok {
+		// Other readers are only used for prompts; handing them to the
+		// editor would have it consume the answers.
+		cmd.Stdin = f
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+	return nil
+}
+
+func validate(newDecoder func(io.Reader) encoding.Decoder, path string, data []byte) error {
+	var v interface{}
+	err := newDecoder(bytes.NewReader(data)).Decode(&v)
+
+	// Errors should point to the file being edited rather than its
+	// temporary copy.
+	var serr *syntax.Error
+	if errors.As(err, &serr) {
+		serr.Filename = path
+	}
+	var lerr *encoding.LoadError
+	if errors.As(err, &lerr) {
+		lerr.Filename = path
+	}
+	return err
+}
+
+// renderError returns the error message followed by the offending line of
+// the source, with a caret pointing at the error position.
+func renderError(err error, src []byte) string {
+	var cursor syntax.Cursor
+	var serr *syntax.Error
+	var lerr *encoding.LoadError
+	switch {
+	case errors.As(err, &serr):
+		cursor = serr.Cursor
+	case errors.As(err, &lerr):
+		cursor = lerr.Cursor
+	}
+
+	lines := strings.Split(string(src), "\n")
+	if cursor.Line < 1 || cursor.Line > len(lines) {
+		return err.Error()
+	}
+	line := strings.TrimRight(lines[cursor.Line-1], "\r")
+
+	// Preserve tabs in the caret line so that it lines up with the source.
+	var caret strings.Builder
+	runes := []rune(line)
+	for i := 0; i < cursor.Column-1; i++ {
+		if i < len(runes) && runes[i] == '\t' {
+			caret.WriteRune('\t')
+		} else {
+			caret.WriteRune(' ')
+		}
+	}
+	caret.WriteRune('^')
+
+	gutter := fmt.Sprintf("%d", cursor.Line)
+	return fmt.Sprintf("%v\n %s | %s\n %s | %s", err, gutter, line, strings.Repeat(" ", len(gutter)), caret.String())
+}
@@ -0,0 +1,189 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeEditor installs an editor script that replaces the edited file with
+// each of the specified contents in turn, and returns the configuration
+// home.
+func fakeEditor(t *testing.T, contents ...string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("the fake editor and XDG configuration paths require a unix system")
+	}
+
+	dir := t.TempDir()
+	for i, content := range contents {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("edit%d", i+1)), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	script := filepath.Join(dir, "editor")
+	err := os.WriteFile(script, []byte(`#!/bin/sh
+n=$(cat "`+dir+`/count" 2>/dev/null || echo 0)
+n=$((n + 1))
+echo $n > "`+dir+`/count"
+[ -f "`+dir+`/edit$n" ] || exit 1
+cp "`+dir+`/edit$n" "$1"
+`), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	home := filepath.Join(dir, "home")
+	if err := os.Mkdir(home, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", script)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("XDG_CONFIG_DIRS", filepath.Join(dir, "sys"))
+	return home
+}
+
+func TestEdit(t *testing.T) {
+	home := fakeEditor(t, "port = \n", "port = 8080\n")
+	path := filepath.Join(home, "boa-edit-test.toml")
+	if err := os.WriteFile(path, []byte("port = 80\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	status := runEdit([]string{"boa-edit-test"}, strings.NewReader("\n"), &stdout, &stderr)
+	if status != 0 {
+		t.Fatalf("expected exit status 0, got %d (stderr: %q)", status, stderr.String())
+	}
+
+	out, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "port = 8080\n" {
+		t.Errorf("unexpected file contents %q", out)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("file mode was not preserved: %v %v", info.Mode(), err)
+	}
+	if !strings.Contains(stderr.String(), path+":1:8:") || !strings.Contains(stderr.String(), " 1 | port = \n   |        ^\n") {
+		t.Errorf("error was not rendered: %q", stderr.String())
+	}
+	if entries, _ := os.ReadDir(home); len(entries) != 1 {
+		t.Errorf("temporary files were left behind: %v", entries)
+	}
+}
+
+func TestEditCreate(t *testing.T) {
+	home := fakeEditor(t, "name: x\n")
+
+	var stdout, stderr bytes.Buffer
+	status := runEdit([]string{"-ext", ".yaml", "app/boa-edit-test"}, strings.NewReader(""), &stdout, &stderr)
+	if status != 0 {
+		t.Fatalf("expected exit status 0, got %d (stderr: %q)", status, stderr.String())
+	}
+	out, err := os.ReadFile(filepath.Join(home, "app", "boa-edit-test.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "name: x\n" {
+		t.Errorf("unexpected file contents %q", out)
+	}
+}
+
+func TestEditSymlink(t *testing.T) {
+	home := fakeEditor(t, "port = 8080\n")
+	target := filepath.Join(t.TempDir(), "target.toml")
+	if err := os.WriteFile(target, []byte("port = 80\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(home, "boa-edit-test.toml")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	status := runEdit([]string{"boa-edit-test"}, strings.NewReader(""), &stdout, &stderr)
+	if status != 0 {
+		t.Fatalf("expected exit status 0, got %d (stderr: %q)", status, stderr.String())
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("the symbolic link was replaced: %v", err)
+	}
+	if out, err := os.ReadFile(target); err != nil || string(out) != "port = 8080\n" {
+		t.Errorf("unexpected contents of the link target: %q %v", out, err)
+	}
+}
+
+func TestEditConfigFileEnv(t *testing.T) {
+	home := fakeEditor(t, "port = 8080\n")
+	path := filepath.Join(t.TempDir(), "override.toml")
+	if err := os.WriteFile(path, []byte("port = 80\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, "boa-edit-test.toml"), []byte("port = 80\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("BOA_EDIT_TEST_CONFIG", path)
+
+	var stdout, stderr bytes.Buffer
+	status := runEdit([]string{"boa-edit-test"}, strings.NewReader(""), &stdout, &stderr)
+	if status != 0 {
+		t.Fatalf("expected exit status 0, got %d (stderr: %q)", status, stderr.String())
+	}
+	if out, err := os.ReadFile(path); err != nil || string(out) != "port = 8080\n" {
+		t.Errorf("unexpected contents of the file in the environment: %q %v", out, err)
+	}
+	if out, err := os.ReadFile(filepath.Join(home, "boa-edit-test.toml")); err != nil || string(out) != "port = 80\n" {
+		t.Errorf("the file of the configuration home was edited: %q %v", out, err)
+	}
+}
+
+func TestEditAbandon(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		edits   []string
+		answers string
+	}{
+		{"quit", []string{"{a: "}, "q\n"},
+		{"eof", []string{"{a: ", "{a: "}, "\n"},
+		{"editor-failure", nil, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			home := fakeEditor(t, tc.edits...)
+			path := filepath.Join(home, "boa-edit-test.json5")
+			if err := os.WriteFile(path, []byte("{a: 1}\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			var stdout, stderr bytes.Buffer
+			status := runEdit([]string{"boa-edit-test"}, strings.NewReader(tc.answers), &stdout, &stderr)
+			if status != 1 {
+				t.Errorf("expected exit status 1, got %d (stderr: %q)", status, stderr.String())
+			}
+			if !strings.Contains(stderr.String(), "abandoned") {
+				t.Errorf("expected changes to be abandoned: %q", stderr.String())
+			}
+			out, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != "{a: 1}\n" {
+				t.Errorf("original file was modified: %q", out)
+			}
+			if entries, _ := os.ReadDir(home); len(entries) != 1 {
+				t.Errorf("temporary files were left behind: %v", entries)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+// Command boa provides utilities for working with configuration files written
+// in any of the languages supported by boa.
+//
+// Usage:
+//
+//	boa <command> [arguments]
+//
+// The commands are:
+//
+//	diff    print the semantic differences between two configuration files
+//	edit    safely edit a configuration file
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"snai.pe/boa"
+	"snai.pe/boa/encoding/dotenv"
+)
+
+func init() {
+	// The commands operate on files given explicitly, so dotenv files are
+	// supported, even though they are not searched for by default.
+	boa.Decoders[".env"] = dotenv.NewDecoder
+	boa.Encoders[".env"] = dotenv.NewEncoder
+}
+
+type command struct {
+	run   func(args []string, stdin io.Reader, stdout, stderr io.Writer) int
+	short string
+}
+
+var commands = map[string]command{
+	"diff": {runDiff, "print the semantic differences between two configuration files"},
+	"edit": {runEdit, "safely edit a configuration file"},
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: boa <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "The commands are:")
+	fmt.Fprintln(w)
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "\t%-8s%s\n", name, commands[name].short)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		if os.Args[1] == "help" || os.Args[1] == "-h" || os.Args[1] == "-help" {
+			usage(os.Stdout)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "boa: unknown command %q\n", os.Args[1])
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	os.Exit(cmd.run(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+}
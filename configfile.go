@@ -0,0 +1,227 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"snai.pe/boa/encoding"
+)
+
+// ConfigFileMode determines how the configuration file given on the command
+// line or in the environment is combined with the search paths.
+type ConfigFileMode int
+
+const (
+	// ReplaceSearch loads the configuration file instead of the files of the
+	// search paths.
+	ReplaceSearch ConfigFileMode = iota
+
+	// AugmentSearch loads the configuration file after the files of the
+	// search paths, as the most important one.
+	AugmentSearch
+)
+
+// ConfigFileEnv returns the name of the environment variable that holds the
+// path to the configuration file of the specified name, which is its stem in
+// uppercase, with a _CONFIG suffix. For instance, the variable of "myapp" and
+// "tools/myapp.toml" is MYAPP_CONFIG.
+func ConfigFileEnv(name string) string {
+	name = path.Base(filepath.ToSlash(name))
+	name = strings.TrimSuffix(name, path.Ext(name))
+	toEnv := func(r rune) rune {
+		if !unicode.In(r, unicode.Letter, unicode.Digit) {
+			return '_'
+		}
+		return unicode.ToUpper(r)
+	}
+	return strings.Map(toEnv, name) + "_CONFIG"
+}
+
+// ConfigFileFlag defines a -config flag in set, which holds the path to a
+// configuration file, and returns a pointer to its value. If set is nil,
+// flag.CommandLine is used.
+//
+// The value is meant to be passed to ConfigFilePaths or Loader.ConfigFile
+// once the flags are parsed.
+func ConfigFileFlag(set *flag.FlagSet) *string {
+	if set == nil {
+		set = flag.CommandLine
+	}
+	return set.String("config", "", "load the configuration from `path` (- for the standard input)")
+}
+
+// ConfigFilePaths returns the search paths of the configuration files with
+// the specified name, overridden by the configuration file at file, such as
+// the value of a -config flag (see ConfigFileFlag). If file is empty, the
+// value of the environment variable named ConfigFileEnv(name) is used
+// instead, and if it is empty too, paths is returned unchanged.
+//
+// Unlike Open, file is a filesystem path, which may be absolute. Its
+// extension determines its configuration language, and must match the one of
+// name, if any. Files without a known extension, including the standard
+// input when file is "-", are in the language of name if it has an
+// extension, or in the language detected from their contents otherwise (see
+// DetectFormat). mode determines whether the file replaces or augments paths:
+//
+//	configFile := boa.ConfigFileFlag(nil)
+//	flag.Parse()
+//
+//	paths, err := boa.ConfigFilePaths("myapp", *configFile, boa.ReplaceSearch, boa.ConfigPaths()...)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	f := boa.Open("myapp", paths...)
+//
+// An error is returned if the file does not exist, or if its language
+// cannot be determined.
+func ConfigFilePaths(name, file string, mode ConfigFileMode, paths ...fs.FS) ([]fs.FS, error) {
+	readStdin := func() ([]byte, error) { return io.ReadAll(os.Stdin) }
+	return configFilePaths(name, file, mode, Decoders, readStdin, paths)
+}
+
+// configFilePaths implements ConfigFilePaths, with the specified languages,
+// and with readStdin returning the contents of the standard input.
+func configFilePaths(name, file string, mode ConfigFileMode, decoders map[string]func(io.Reader) encoding.Decoder, readStdin func() ([]byte, error), paths []fs.FS) ([]fs.FS, error) {
+	if file == "" {
+		file = os.Getenv(ConfigFileEnv(name))
+	}
+	if file == "" {
+		return paths, nil
+	}
+
+	nameExt := filepath.Ext(name)
+	stem := path.Clean(filepath.ToSlash(strings.TrimSuffix(name, nameExt)))
+	var fsys fs.FS
+	if file == "-" {
+		data, err := readStdin()
+		if err != nil {
+			return nil, err
+		}
+		read := func() ([]byte, error) { return data, nil }
+		ext, err := configFileExt("the standard input", "", nameExt, read, decoders)
+		if err != nil {
+			return nil, err
+		}
+		fsys = stdinFS{name: stem + ext, data: data}
+	} else {
+		if _, err := os.Stat(file); err != nil {
+			return nil, err
+		}
+		read := func() ([]byte, error) {
+			f, err := os.Open(file)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			return io.ReadAll(io.LimitReader(f, detectLen))
+		}
+		ext, err := configFileExt(file, filepath.Ext(file), nameExt, read, decoders)
+		if err != nil {
+			return nil, err
+		}
+		fsys = NewSingleFileFS(stem+ext, file)
+	}
+	switch mode {
+	case ReplaceSearch:
+		return []fs.FS{fsys}, nil
+	case AugmentSearch:
+		return append(paths[:len(paths):len(paths)], fsys), nil
+	default:
+		panic("invalid ConfigFileMode")
+	}
+}
+
+// configFileExt returns the extension of the configuration language of the
+// configuration file described by desc, whose own extension is ext, and
+// whose start is returned by read. nameExt is the extension of the
+// configuration name that the file stands for.
+func configFileExt(desc, ext, nameExt string, read func() ([]byte, error), decoders map[string]func(io.Reader) encoding.Decoder) (string, error) {
+	if _, ok := decoders[ext]; ok {
+		if nameExt != "" && ext != nameExt {
+			return "", fmt.Errorf("%s: expected a %s configuration file", desc, nameExt)
+		}
+		return ext, nil
+	}
+	if nameExt != "" {
+		return nameExt, nil
+	}
+
+	data, err := read()
+	if err != nil {
+		return "", err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		// Any language will do to decode nothing.
+		return ".toml", nil
+	}
+	ext = DetectFormat(data)
+	if _, ok := decoders[ext]; !ok {
+		return "", fmt.Errorf("cannot detect the configuration language of %s", desc)
+	}
+	return ext, nil
+}
+
+// stdinFS holds the contents of the standard input, under the name of the
+// configuration file that it stands for.
+type stdinFS struct {
+	name string
+	data []byte
+}
+
+func (f stdinFS) Open(name string) (fs.File, error) {
+	if name != f.name {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return newBytesFile(name, f.data, 0444, time.Time{}), nil
+}
+
+// bytesFile is a read-only fs.File whose contents are held in memory.
+type bytesFile struct {
+	*bytes.Reader
+	info bytesFileInfo
+}
+
+// newBytesFile returns a new bytesFile with the specified name, contents,
+// mode and modification time.
+func newBytesFile(name string, data []byte, mode fs.FileMode, modTime time.Time) fs.File {
+	return &bytesFile{
+		Reader: bytes.NewReader(data),
+		info: bytesFileInfo{
+			name:    path.Base(name),
+			size:    int64(len(data)),
+			mode:    mode,
+			modTime: modTime,
+		},
+	}
+}
+
+func (f *bytesFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *bytesFile) Close() error               { return nil }
+
+type bytesFileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i bytesFileInfo) Name() string       { return i.name }
+func (i bytesFileInfo) Size() int64        { return i.size }
+func (i bytesFileInfo) Mode() fs.FileMode  { return i.mode }
+func (i bytesFileInfo) ModTime() time.Time { return i.modTime }
+func (i bytesFileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i bytesFileInfo) Sys() interface{}   { return nil }
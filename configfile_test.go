@@ -0,0 +1,154 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestConfigFile(t *testing.T) {
+	if name := ConfigFileEnv("tools/my-app.toml"); name != "MY_APP_CONFIG" {
+		t.Fatalf("expected MY_APP_CONFIG, got %q", name)
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "custom.json5")
+	if err := os.WriteFile(file, []byte("{ port: 8080 }"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	type Config struct {
+		Name string
+		Port int
+	}
+	home := fstest.MapFS{
+		"myapp.toml": {Data: []byte("name = \"home\"\nport = 80\n")},
+	}
+
+	flags := flag.NewFlagSet("myapp", flag.ContinueOnError)
+	configFile := ConfigFileFlag(flags)
+	if err := flags.Parse([]string{"-config", file}); err != nil {
+		t.Fatal(err)
+	}
+
+	tcases := []struct {
+		name     string
+		file     string
+		env      string
+		mode     ConfigFileMode
+		expected Config
+	}{
+		{name: "none", expected: Config{Name: "home", Port: 80}},
+		{name: "replace", file: *configFile, mode: ReplaceSearch, expected: Config{Port: 8080}},
+		{name: "augment", file: *configFile, mode: AugmentSearch, expected: Config{Name: "home", Port: 8080}},
+		{name: "env", env: file, mode: AugmentSearch, expected: Config{Name: "home", Port: 8080}},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			t.Setenv("MYAPP_CONFIG", tcase.env)
+
+			var config Config
+			err := NewLoader().Paths(home).Files("myapp").ConfigFile(tcase.file, tcase.mode).Load(&config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if config != tcase.expected {
+				t.Fatalf("expected %+v, got %+v", tcase.expected, config)
+			}
+		})
+	}
+
+	_, err := ConfigFilePaths("myapp", filepath.Join(dir, "missing.toml"), ReplaceSearch)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected not exist error, got %v", err)
+	}
+}
+
+func TestConfigFileLanguage(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, []byte(data), 0666); err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+	conf := write("app.conf", "port = 8080\n")
+	toml := write("app.toml", "port = 8080\n")
+	unknown := write("app.txt", "port 8080\n")
+
+	type Config struct {
+		Port int
+	}
+
+	tcases := []struct {
+		name string
+		file string
+		err  bool
+	}{
+		{name: "myapp", file: toml},
+		{name: "myapp.toml", file: toml},
+		{name: "myapp", file: conf},
+		{name: "myapp.toml", file: conf},
+		{name: "myapp.yaml", file: toml, err: true},
+		{name: "myapp", file: unknown, err: true},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name+"/"+filepath.Base(tcase.file), func(t *testing.T) {
+			var config Config
+			err := NewLoader().Paths(fstest.MapFS{}).Files(tcase.name).ConfigFile(tcase.file, ReplaceSearch).Load(&config)
+			if tcase.err {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", config)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if config.Port != 8080 {
+				t.Fatalf("expected port 8080, got %+v", config)
+			}
+		})
+	}
+}
+
+func TestConfigFileMultipleNames(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "custom.toml")
+	if err := os.WriteFile(file, []byte("port = 8080\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	type Config struct {
+		Name  string
+		Port  int
+		Debug bool
+	}
+	home := fstest.MapFS{
+		"base.toml":     {Data: []byte("name = \"base\"\nport = 80\n")},
+		"override.toml": {Data: []byte("debug = true\n")},
+	}
+
+	// Only the search of the first name is replaced.
+	var config Config
+	err := NewLoader().Paths(home).Files("base", "override").ConfigFile(file, ReplaceSearch).Load(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Config{Port: 8080, Debug: true}
+	if config != expected {
+		t.Fatalf("expected %+v, got %+v", expected, config)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"snai.pe/boa/encoding/json5"
+	"snai.pe/boa/encoding/toml"
+	"snai.pe/boa/encoding/yaml"
+)
+
+// detectLen is the number of bytes at the start of inputs that DetectFormat
+// is given.
+const detectLen = 64 << 10
+
+// formats are the configuration languages that DetectFormat detects, in
+// order. JSON5 goes first, since JSON documents are also YAML documents.
+var formats = []struct {
+	ext    string
+	detect func([]byte) bool
+}{
+	{".json5", json5.Detect},
+	{".toml", toml.Detect},
+	{".yaml", yaml.Detect},
+}
+
+// DetectFormat returns the file extension of the configuration language of
+// data, which is the start of a document, or the empty string if it is not
+// recognized. The detected languages are:
+//
+//   - JSON5 (".json5"): objects and arrays;
+//   - TOML (".toml"): documents that start with a table header or a key/value
+//     pair;
+//   - YAML (".yaml"): documents that start with a block mapping or sequence,
+//     or with a directive.
+//
+// The language is detected from the first tokens of data, so that comments
+// are ignored, but it is not validated.
+func DetectFormat(data []byte) string {
+	for _, format := range formats {
+		if format.detect(data) {
+			return format.ext
+		}
+	}
+	return ""
+}
+
+// detectInput detects the configuration language of in, and returns a
+// reader with the same contents as in.
+func detectInput(in io.Reader) (string, io.Reader, error) {
+	br := bufio.NewReaderSize(in, detectLen)
+	data, err := br.Peek(detectLen)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return "", nil, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return "", br, nil
+	}
+	ext := DetectFormat(data)
+	if ext == "" {
+		return "", nil, fmt.Errorf("cannot detect the configuration language of %s", inputName(in))
+	}
+	return ext, br, nil
+}
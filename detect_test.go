@@ -0,0 +1,144 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"snai.pe/boa/encoding"
+	"snai.pe/boa/encoding/yaml"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tcases := []struct {
+		input    string
+		expected string
+	}{
+		{`{"name": "value"}`, ".json5"},
+		{"// comment\n{ name: 'value', }", ".json5"},
+		{"[1, 2, 3]", ".json5"},
+		{"[[1], []]", ".json5"},
+		{"{}", ".json5"},
+		{"# comment\nname = \"value\"", ".toml"},
+		{"[server]\nport = 80", ".toml"},
+		{"[[servers]]\nport = 80", ".toml"},
+		{"server.port = 80", ".toml"},
+		{"\"quoted key\" = 1", ".toml"},
+		{"name: value", ".yaml"},
+		{"# comment\nserver:\n  port: 80", ".yaml"},
+		{"- a\n- b", ".yaml"},
+		{"---\nname: value", ".yaml"},
+		{"%YAML 1.2\n---\nname: value", ".yaml"},
+		{"\"quoted\": value", ".yaml"},
+		{"just some text", ""},
+		{"", ""},
+	}
+
+	for _, tcase := range tcases {
+		if ext := DetectFormat([]byte(tcase.input)); ext != tcase.expected {
+			t.Errorf("%q: expected %q, got %q", tcase.input, tcase.expected, ext)
+		}
+	}
+}
+
+func TestDecodeReader(t *testing.T) {
+	type Config struct {
+		Name string
+		Port int
+	}
+
+	inputs := []string{
+		"{ name: \"json5\", port: 80 }",
+		"name = \"toml\"\nport = 80\n",
+		"name: yaml\nport: 80\n",
+	}
+	for _, input := range inputs {
+		var config Config
+		if err := NewLoader().NewDecoder(strings.NewReader(input)).Decode(&config); err != nil {
+			t.Fatalf("%q: %v", input, err)
+		}
+		if config.Port != 80 || config.Name == "" {
+			t.Fatalf("%q: unexpected config %+v", input, config)
+		}
+	}
+
+	// The Format option takes precedence over detection.
+	var config Config
+	dec := NewLoader().NewDecoder(bytes.NewReader([]byte("name = \"toml\"\n"))).Option(Format(".yaml"))
+	if err := dec.Decode(&config); err == nil {
+		t.Fatalf("expected the input to be decoded as YAML, got %+v", config)
+	}
+	dec = NewLoader().NewDecoder(strings.NewReader("just some text")).Option(Format(".toml"))
+	if err := dec.Decode(&config); err == nil || strings.Contains(err.Error(), "cannot detect") {
+		t.Fatalf("expected the input to be decoded as TOML, got %v", err)
+	}
+
+	err := NewLoader().NewDecoder(strings.NewReader("just some text")).Decode(&config)
+	if err == nil || !strings.Contains(err.Error(), "cannot detect") {
+		t.Fatalf("expected detection error, got %v", err)
+	}
+
+	config = Config{}
+	if err := NewLoader().NewDecoder(strings.NewReader("")).Decode(&config); err != nil {
+		t.Fatalf("expected empty input to decode, got %v", err)
+	}
+}
+
+func TestDecodeEmptyWithoutTOML(t *testing.T) {
+	used := 0
+	loader := NewLoader().Decoder(".toml", nil).Decoder(".a", func(in io.Reader) encoding.Decoder {
+		used++
+		return yaml.NewDecoder(in)
+	})
+
+	// Without a TOML decoder, empty inputs must always be decoded with the
+	// first decoder in lexical order of the extensions.
+	for i := 0; i < 20; i++ {
+		var config interface{}
+		if err := loader.NewDecoder(strings.NewReader("")).Decode(&config); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if used != 20 {
+		t.Errorf("expected the .a decoder to be used 20 times, got %d", used)
+	}
+}
+
+func TestConfigFileStdin(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(path, []byte("port: 8080\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	stdin := os.Stdin
+	os.Stdin = f
+	defer func() { os.Stdin = stdin }()
+
+	var config struct {
+		Port int
+	}
+	loader := NewLoader().Paths().Files("myapp").ConfigFile("-", ReplaceSearch)
+	// The standard input is read once, and loaded on every Load.
+	for i := 0; i < 2; i++ {
+		config.Port = 0
+		if err := loader.Load(&config); err != nil {
+			t.Fatal(err)
+		}
+		if config.Port != 8080 {
+			t.Fatalf("load %d: expected port 8080, got %d", i, config.Port)
+		}
+	}
+}
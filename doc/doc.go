@@ -0,0 +1,114 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+// Package doc generates reference documentation for configuration types.
+//
+// The documentation lists every key of the configuration, along with its
+// type, default value, help text (from the `help` struct tag), the
+// environment variables it can be set from, and its allowed values when they
+// are known. Since it is generated from the configuration type itself, it
+// does not drift from it.
+//
+// The generators are encoders: the value passed to Encode is documented, and
+// its current contents are used as defaults. The options that are used to
+// load the configuration can be passed to the generators, so that the
+// documented keys and environment variables match:
+//
+//	doc.NewMarkdownEncoder(os.Stdout).Option(
+//		doc.Format(".toml"),
+//		doc.Title("myapp"),
+//		boa.AutomaticEnv("MYAPP"),
+//	).Encode(&defaults)
+package doc
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+
+	boaenc "snai.pe/boa/encoding"
+	"snai.pe/boa/internal/encutil"
+	"snai.pe/boa/internal/reflectutil"
+)
+
+type generator struct {
+	out    io.Writer
+	title  string
+	format string
+
+	boaenc.CommonOptions
+	boaenc.DecoderOptions
+}
+
+// Option represents an option specific to documentation generators.
+type Option func(*generator)
+
+// Title sets the name of the program, or configuration, that is documented.
+func Title(title string) Option {
+	return func(gen *generator) {
+		gen.title = title
+	}
+}
+
+// Format sets the configuration language for which keys are documented, by
+// file extension (e.g. ".toml", ".json5", or ".yaml").
+//
+// The default naming convention and struct tag of that language are used
+// to name keys; the naming convention can still be overriden with the
+// NamingConvention option. The default format is TOML.
+func Format(ext string) Option {
+	if _, ok := formats[ext]; !ok {
+		panic(fmt.Sprintf("unknown configuration format %q", ext))
+	}
+	return func(gen *generator) {
+		gen.format = ext
+	}
+}
+
+type format struct {
+	naming boaenc.NamingConvention
+	tag    string
+}
+
+var formats = map[string]format{
+	".toml":  {boaenc.SnakeCase, "toml"},
+	".json5": {boaenc.CamelCase, "json"},
+	".json":  {boaenc.CamelCase, "json"},
+	".yaml":  {boaenc.KebabCase, "yaml"},
+	".yml":   {boaenc.KebabCase, "yaml"},
+	".env":   {boaenc.ScreamingSnakeCase, "dotenv"},
+}
+
+func (gen *generator) option(opts ...interface{}) {
+	for _, opt := range opts {
+		switch setopt := opt.(type) {
+		case Option:
+			setopt(gen)
+		case boaenc.CommonOption:
+			setopt(&gen.CommonOptions)
+		case boaenc.DecoderOption:
+			setopt(&gen.DecoderOptions)
+		default:
+			panic(fmt.Sprintf("%T is not a common option, a decoder option, nor a documentation option.", opt))
+		}
+	}
+}
+
+func (gen *generator) describe(v interface{}) []reflectutil.Description {
+	f := formats[gen.format]
+	if gen.format == "" {
+		f = formats[".toml"]
+	}
+	naming := gen.NamingConvention
+	if naming == nil {
+		naming = f.naming
+	}
+	parser := encutil.StructTagParser{Tag: f.tag}
+	env := reflectutil.Env{
+		Naming:    gen.EnvNamingConvention,
+		Separator: gen.EnvSeparator,
+	}
+	return reflectutil.Describe(reflect.ValueOf(v), naming, parser, gen.AutomaticEnv, gen.EnvPrefix, env)
+}
@@ -0,0 +1,180 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package doc
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"snai.pe/boa/encoding"
+)
+
+type testConfig struct {
+	Name    string        `help:"Name of the service."`
+	Debug   bool          `help:"Enable debug logging."`
+	Timeout time.Duration `help:"Request timeout."`
+	Path    []string      `env:"SEARCH_PATH" help:"Search path."`
+	Server  struct {
+		Host *string
+		Port int `help:"Port to listen on." toml:"listen_port"`
+	} `help:"Server settings."`
+	Users   map[string]string
+	Ignored string `toml:"-"`
+}
+
+func defaults() *testConfig {
+	cfg := &testConfig{
+		Name:    "svc",
+		Timeout: 90 * time.Second,
+		Users:   map[string]string{"root": "admin", "alice": "user"},
+	}
+	cfg.Server.Port = 8080
+	return cfg
+}
+
+var expectedMarkdown = "# myapp configuration reference\n" + `
+## ` + "`name`" + `
+
+Name of the service.
+
+- Type: string
+- Default: ` + "`\"svc\"`" + `
+- Environment: ` + "`MYAPP_NAME`" + `
+
+## ` + "`debug`" + `
+
+Enable debug logging.
+
+- Type: boolean
+- Default: ` + "`false`" + `
+- Environment: ` + "`MYAPP_DEBUG`" + `
+- Allowed values: ` + "`true`, `false`" + `
+
+## ` + "`timeout`" + `
+
+Request timeout.
+
+- Type: duration
+- Default: ` + "`1m30s`" + `
+- Environment: ` + "`MYAPP_TIMEOUT`" + `
+
+## ` + "`path`" + `
+
+Search path.
+
+- Type: list of string
+- Environment: ` + "`SEARCH_PATH`" + `
+
+## ` + "`server`" + `
+
+Server settings.
+
+- Type: section
+
+## ` + "`server.host`" + `
+
+- Type: string
+- Default: ` + "`\"\"`" + `
+- Environment: ` + "`MYAPP_SERVER_HOST`" + `
+
+## ` + "`server.listen_port`" + `
+
+Port to listen on.
+
+- Type: integer
+- Default: ` + "`8080`" + `
+- Environment: ` + "`MYAPP_SERVER_PORT`" + `
+
+## ` + "`users`" + `
+
+- Type: map of string
+
+## ` + "`users.alice`" + `
+
+- Type: string
+- Default: ` + "`\"user\"`" + `
+- Environment: ` + "`MYAPP_USERS_ALICE`" + `
+
+## ` + "`users.root`" + `
+
+- Type: string
+- Default: ` + "`\"admin\"`" + `
+- Environment: ` + "`MYAPP_USERS_ROOT`" + `
+`
+
+func automaticEnv(prefix string) encoding.DecoderOption {
+	return func(opts *encoding.DecoderOptions) {
+		opts.AutomaticEnv = true
+		opts.EnvPrefix = prefix
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	var out strings.Builder
+	err := NewMarkdownEncoder(&out).Option(Title("myapp"), automaticEnv("MYAPP")).Encode(defaults())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != expectedMarkdown {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", out.String(), expectedMarkdown)
+	}
+}
+
+func TestMarkdownFormat(t *testing.T) {
+	var out strings.Builder
+	err := NewMarkdownEncoder(&out).Option(Format(".yaml")).Encode(defaults())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"`server.port`", "`users.root`"} {
+		if !strings.Contains(out.String(), "## "+key+"\n") {
+			t.Errorf("expected key %s in output:\n%s", key, out.String())
+		}
+	}
+	// Without AutomaticEnv, only the explicit `env` tags apply.
+	if n := strings.Count(out.String(), "- Environment:"); n != 1 || !strings.Contains(out.String(), "- Environment: `SEARCH_PATH`\n") {
+		t.Errorf("expected only SEARCH_PATH without AutomaticEnv:\n%s", out.String())
+	}
+}
+
+func TestMan(t *testing.T) {
+	var out strings.Builder
+	err := NewManEncoder(&out).Option(Title("my-app"), automaticEnv("MYAPP")).Encode(defaults())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		".TH \"MY\\-APP\" 5 \"\" \"\" \"Configuration reference\"\n.SH NAME\nmy\\-app \\- configuration reference\n",
+		".TP\n.B server.listen_port\nPort to listen on.\n.IP\nType: integer\n.br\nDefault: 8080\n.br\nEnvironment: MYAPP_SERVER_PORT\n",
+		".TP\n.B users\nType: map of string\n",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in output:\n%s", expected, out.String())
+		}
+	}
+}
+
+type recursiveNode struct {
+	Name string
+	Next *recursiveNode
+}
+
+func TestMarkdownRecursive(t *testing.T) {
+	var out strings.Builder
+	err := NewMarkdownEncoder(&out).Encode(&recursiveNode{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"`name`", "`next`", "`next.name`", "`next.next`"} {
+		if !strings.Contains(out.String(), "## "+key+"\n") {
+			t.Errorf("expected key %s in output:\n%s", key, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "`next.next.name`") {
+		t.Errorf("recursive type should not be expanded twice:\n%s", out.String())
+	}
+}
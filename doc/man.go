@@ -0,0 +1,87 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package doc
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"snai.pe/boa/encoding"
+	"snai.pe/boa/internal/reflectutil"
+)
+
+type manEncoder struct {
+	generator
+}
+
+// NewManEncoder returns an encoder that writes the reference documentation
+// of the encoded configuration value into out, as a section 5 man page in
+// roff format.
+func NewManEncoder(out io.Writer) encoding.Encoder {
+	return &manEncoder{generator{out: out}}
+}
+
+func (enc *manEncoder) Option(opts ...interface{}) encoding.Encoder {
+	enc.option(opts...)
+	return enc
+}
+
+// roff escapes s so that it is rendered verbatim in a man page.
+func roff(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+func (enc *manEncoder) Encode(v interface{}) error {
+	var out strings.Builder
+
+	name := enc.title
+	if name == "" {
+		name = "config"
+	}
+	fmt.Fprintf(&out, ".TH \"%s\" 5 \"\" \"\" \"Configuration reference\"\n", roff(strings.ToUpper(name)))
+	fmt.Fprintf(&out, ".SH NAME\n%s \\- configuration reference\n", roff(name))
+	fmt.Fprintf(&out, ".SH KEYS\n")
+
+	for _, desc := range enc.describe(v) {
+		fmt.Fprintf(&out, ".TP\n.B %s\n", roff(strings.Join(desc.Path, ".")))
+		for _, line := range desc.Help {
+			if line == "" {
+				out.WriteString(".br\n")
+				continue
+			}
+			fmt.Fprintf(&out, "%s\n", roff(line))
+		}
+
+		attrs := []string{"Type: " + reflectutil.TypeName(desc.Value.Type())}
+		if def, ok := reflectutil.FormatValue(desc.Value); ok {
+			attrs = append(attrs, "Default: "+def)
+		}
+		if len(desc.Env) > 0 {
+			attrs = append(attrs, "Environment: "+strings.Join(desc.Env, ", "))
+		}
+		if len(desc.Allowed) > 0 {
+			attrs = append(attrs, "Allowed values: "+strings.Join(desc.Allowed, ", "))
+		}
+		if len(desc.Help) > 0 {
+			out.WriteString(".IP\n")
+		}
+		for i, attr := range attrs {
+			if i > 0 {
+				out.WriteString(".br\n")
+			}
+			fmt.Fprintf(&out, "%s\n", roff(attr))
+		}
+	}
+
+	_, err := io.WriteString(enc.out, out.String())
+	return err
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package doc
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"snai.pe/boa/encoding"
+	"snai.pe/boa/internal/reflectutil"
+)
+
+type markdownEncoder struct {
+	generator
+}
+
+// NewMarkdownEncoder returns an encoder that writes the Markdown reference
+// documentation of the encoded configuration value into out.
+func NewMarkdownEncoder(out io.Writer) encoding.Encoder {
+	return &markdownEncoder{generator{out: out}}
+}
+
+func (enc *markdownEncoder) Option(opts ...interface{}) encoding.Encoder {
+	enc.option(opts...)
+	return enc
+}
+
+func (enc *markdownEncoder) Encode(v interface{}) error {
+	var out strings.Builder
+
+	title := "Configuration reference"
+	if enc.title != "" {
+		title = enc.title + " configuration reference"
+	}
+	fmt.Fprintf(&out, "# %s\n", title)
+
+	code := func(s string) string {
+		// Use a delimiter that is longer than any backtick run in s.
+		delim := "`"
+		for strings.Contains(s, delim) {
+			delim += "`"
+		}
+		if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+			s = " " + s + " "
+		}
+		return delim + s + delim
+	}
+
+	for _, desc := range enc.describe(v) {
+		fmt.Fprintf(&out, "\n## %s\n", code(strings.Join(desc.Path, ".")))
+		if len(desc.Help) > 0 {
+			fmt.Fprintf(&out, "\n%s\n", strings.Join(desc.Help, "\n"))
+		}
+
+		fmt.Fprintf(&out, "\n- Type: %s\n", reflectutil.TypeName(desc.Value.Type()))
+		if def, ok := reflectutil.FormatValue(desc.Value); ok {
+			fmt.Fprintf(&out, "- Default: %s\n", code(def))
+		}
+		if len(desc.Env) > 0 {
+			env := make([]string, len(desc.Env))
+			for i, e := range desc.Env {
+				env[i] = code(e)
+			}
+			fmt.Fprintf(&out, "- Environment: %s\n", strings.Join(env, ", "))
+		}
+		if len(desc.Allowed) > 0 {
+			allowed := make([]string, len(desc.Allowed))
+			for i, a := range desc.Allowed {
+				allowed[i] = code(a)
+			}
+			fmt.Fprintf(&out, "- Allowed values: %s\n", strings.Join(allowed, ", "))
+		}
+	}
+
+	_, err := io.WriteString(enc.out, out.String())
+	return err
+}
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
@@ -18,44 +19,62 @@ import (
 	"snai.pe/boa/encoding/json5"
 	"snai.pe/boa/encoding/toml"
 	"snai.pe/boa/encoding/yaml"
+	"snai.pe/boa/internal/fsutil"
 )
 
 // Decoders map filename extensions to decoders.  By default, the following
-// coniguration languages are associated with the extensions:
+// configuration languages are associated with the extensions:
 //
 //   - JSON5: .json and .json5
 //   - TOML: .toml
+//   - YAML: .yaml and .yml
 //
 // The contents of this map controls how the Decoder type deduces which
 // decoder to use based on the file extension of the input.
-var Decoders = map[string]func(io.Reader) encoding.Decoder{
-	".toml":  toml.NewDecoder,
-	".json5": json5.NewDecoder,
-	".json":  json5.NewDecoder,
-	".yaml":  yaml.NewDecoder,
-	".yml":   yaml.NewDecoder,
+//
+// Other languages, like dotenv, are not searched for by default, and must be
+// added explicitly, for instance with Loader.Decoder(".env", dotenv.NewDecoder).
+var Decoders = builtinDecoders()
+
+func builtinDecoders() map[string]func(io.Reader) encoding.Decoder {
+	return map[string]func(io.Reader) encoding.Decoder{
+		".toml":  toml.NewDecoder,
+		".json5": json5.NewDecoder,
+		".json":  json5.NewDecoder,
+		".yaml":  yaml.NewDecoder,
+		".yml":   yaml.NewDecoder,
+	}
 }
 
 // Encoders map filename extensions to encoders.  By default, the following
-// coniguration languages are associated with the extensions:
+// configuration languages are associated with the extensions:
 //
 //   - JSON5: .json and .json5
 //   - TOML: .toml
+//   - YAML: .yaml and .yml
 //
 // The contents of this map controls how the Encoder type deduces which
 // encoder to use based on the file extension of the output.
-var Encoders = map[string]func(io.Writer) encoding.Encoder{
-	".toml":  toml.NewEncoder,
-	".json5": json5.NewEncoder,
-	".json":  json5.NewEncoder,
-	".yaml":  yaml.NewEncoder,
-	".yml":   yaml.NewEncoder,
+//
+// Other languages, like dotenv, must be added explicitly, for instance with
+// Loader.Encoder(".env", dotenv.NewEncoder).
+var Encoders = builtinEncoders()
+
+func builtinEncoders() map[string]func(io.Writer) encoding.Encoder {
+	return map[string]func(io.Writer) encoding.Encoder{
+		".toml":  toml.NewEncoder,
+		".json5": json5.NewEncoder,
+		".json":  json5.NewEncoder,
+		".yaml":  yaml.NewEncoder,
+		".yml":   yaml.NewEncoder,
+	}
 }
 
 // A Decoder reads and decodes a configuration from an input file.
 type Decoder struct {
-	in   encoding.StatableReader
-	opts []interface{}
+	in       io.Reader
+	opts     []interface{}
+	decoders map[string]func(io.Reader) encoding.Decoder
 }
 
 // NewDecoder returns a new Decoder that reads from `in`.
@@ -64,10 +83,14 @@ type Decoder struct {
 // file extension of its file path.  The file-extension-to-decoder mapping
 // is defined by the contents of the Decoders map in this package.
 //
+// If the input has no name with a known extension, like the standard input or
+// a bytes.Reader, its configuration language is the one set by the Format
+// option, or is otherwise detected from its contents (see DetectFormat).
+//
 // To only use one specific configuration language, do not use this decoder:
 // Use instead the decoder for the chosen language in snai.pe/boa/encoding.
-func NewDecoder(in encoding.StatableReader) *Decoder {
-	return &Decoder{in: in, opts: append(([]interface{})(nil), defaultDecoderOptions...)}
+func NewDecoder(in io.Reader) *Decoder {
+	return defaultLoader().NewDecoder(in)
 }
 
 func (dec *Decoder) Option(opts ...interface{}) encoding.Decoder {
@@ -76,48 +99,82 @@ func (dec *Decoder) Option(opts ...interface{}) encoding.Decoder {
 }
 
 func (dec *Decoder) Decode(v interface{}) error {
+	decoders := dec.decoders
+	if decoders == nil {
+		decoders = Decoders
+	}
+	var opts encoding.DecoderOptions
+	for _, opt := range dec.opts {
+		if opt, ok := opt.(DecoderOption); ok {
+			opt(&opts)
+		}
+	}
+	decoderOpts := dec.opts
+	if opts.EnvFormat != "" {
+		// Parse structured environment variables with the languages of
+		// this decoder rather than with the package-level ones.
+		newEnvDecoder, ok := decoders[opts.EnvFormat]
+		if !ok {
+			return fmt.Errorf("no known decoder for file extension %q", opts.EnvFormat)
+		}
+		decoderOpts = append(decoderOpts[:len(decoderOpts):len(decoderOpts)], DecoderOption(func(opts *encoding.DecoderOptions) {
+			opts.EnvDecoder = newEnvDecoder
+		}))
+	}
 
-	decode := func(in encoding.StatableReader) error {
-		var name string
+	decode := func(in io.Reader) error {
 		// We need to determine the name of the input reader in order to
 		// infer which decoder to use from the extension.
-		info, err := in.Stat()
-		if err != nil {
-			// Some implementations of Stat() fail when the underlying file is
-			// gone. Try to see if the reader implements Name() as a last resort.
-			type Namer interface {
-				Name() string
-			}
-
-			if namer, ok := in.(Namer); !ok {
-				return err
+		name := inputName(in)
+		ext := filepath.Ext(name)
+		decoder, ok := decoders[ext]
+		if !ok && in != discard {
+			if opts.Format != "" {
+				ext = opts.Format
 			} else {
-				name = namer.Name()
+				var err error
+				ext, in, err = detectInput(in)
+				if err != nil {
+					return err
+				}
+				if name != "" {
+					// Keep the name of the input for error messages.
+					in = namedReader{Reader: in, name: name}
+				}
 			}
-		} else {
-			name = info.Name()
+			decoder, ok = decoders[ext]
 		}
-		ext := filepath.Ext(name)
-		if in == discard {
+		if in == discard || ext == "" {
 			// HACK: if the config is the discard file, it means no config file
 			// matched in the file set. Use the TOML decoder (though it could
 			// have been another decoder) to ensure things like AutomaticEnv
-			// still work.
+			// still work. The same goes for empty inputs. Without a TOML
+			// decoder, the first extension in lexical order is used, so that
+			// the choice does not depend on the map iteration order.
 			ext = ".toml"
+			if _, ok := decoders[ext]; !ok {
+				ext = ""
+				for k := range decoders {
+					if ext == "" || k < ext {
+						ext = k
+					}
+				}
+			}
+			decoder, ok = decoders[ext]
 		}
 
-		decoder, ok := Decoders[ext]
 		if !ok {
 			return fmt.Errorf("no known decoder for file extension %q", ext)
 		}
-		return decoder(in).Option(dec.opts...).Decode(v)
+		return decoder(in).Option(decoderOpts...).Decode(v)
 	}
 
 	switch in := dec.in.(type) {
 	case *FileSet:
+		in.ctx = opts.Context
 		for {
-			keys := make([]string, 0, len(Decoders))
-			for k, _ := range Decoders {
+			keys := make([]string, 0, len(decoders))
+			for k, _ := range decoders {
 				keys = append(keys, k)
 			}
 			sort.Strings(keys)
@@ -141,10 +198,36 @@ func (dec *Decoder) Decode(v interface{}) error {
 	}
 }
 
+// inputName returns the name of the file that in reads, or the empty string
+// if it is unknown.
+func inputName(in io.Reader) string {
+	if stater, ok := in.(interface{ Stat() (fs.FileInfo, error) }); ok {
+		if info, err := stater.Stat(); err == nil && info != nil {
+			return info.Name()
+		}
+	}
+	// Some implementations of Stat() fail when the underlying file is
+	// gone. Try to see if the reader implements Name() as a last resort.
+	if namer, ok := in.(interface{ Name() string }); ok {
+		return namer.Name()
+	}
+	return ""
+}
+
+type namedReader struct {
+	io.Reader
+	name string
+}
+
+func (r namedReader) Name() string {
+	return r.name
+}
+
 // An Encoder encodes and writes a configuration into an output file.
 type Encoder struct {
-	out  encoding.StatableWriter
-	opts []interface{}
+	out      encoding.StatableWriter
+	opts     []interface{}
+	encoders map[string]func(io.Writer) encoding.Encoder
 }
 
 // NewEncoder returns a new encoder that writes into `out`.
@@ -156,7 +239,7 @@ type Encoder struct {
 // To only use one specific configuration language, do not use this encoder:
 // Use instead the encoder for the chosen language in snai.pe/boa/encoding.
 func NewEncoder(out encoding.StatableWriter) *Encoder {
-	return &Encoder{out: out, opts: append(([]interface{})(nil), defaultEncoderOptions...)}
+	return defaultLoader().NewEncoder(out)
 }
 
 func (enc *Encoder) Option(opts ...interface{}) encoding.Encoder {
@@ -186,7 +269,11 @@ func (enc *Encoder) Encode(v interface{}) error {
 	}
 	ext := filepath.Ext(name)
 
-	encoder, ok := Encoders[ext]
+	encoders := enc.encoders
+	if encoders == nil {
+		encoders = Encoders
+	}
+	encoder, ok := encoders[ext]
 	if !ok {
 		return fmt.Errorf("no known decoder for file extension %q", ext)
 	}
@@ -207,16 +294,18 @@ func (enc *Encoder) Encode(v interface{}) error {
 // Custom file extensions are not supported, and one of the decoders in
 // snai.pe/boa/encoding must be used instead.
 func Load(name string, v interface{}) error {
-	f := Open(name)
-	defer f.Close()
-
-	return NewDecoder(f).Decode(v)
+	return defaultLoader().Files(name).Load(v)
 }
 
 // Save saves the specified value in v into a named configuration file.
 //
-// The name is interpreted relative to the return value of ConfigHome(). To
-// save to arbitrary file paths, use os.Create and NewEncoder instead.
+// The name is interpreted relative to the return value of ConfigHome(), or to
+// the FS set by SetConfigHomeFS if it is a WritableFS. To save to arbitrary
+// file paths, use os.Create and NewEncoder instead.
+//
+// The file is replaced atomically, so that it is never left truncated, and
+// keeps its mode and ownership. Backups of the replaced file are kept if the
+// Backups option is set.
 //
 // The configuration language is deduced based on the file extension of the
 // specified path:
@@ -227,27 +316,83 @@ func Load(name string, v interface{}) error {
 // Custom file extensions are not supported, and one of the decoders in
 // snai.pe/boa/encoding must be used instead.
 func Save(name string, v interface{}) error {
-	if filepath.IsAbs(name) {
-		panic("Save does not take absolute paths; use os.Create and NewEncoder instead.")
-	}
-	if strings.HasPrefix(name, "."+string(filepath.Separator)) || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
-		panic("Save does not take cwd-relative paths; use os.Create and NewEncoder instead.")
-	}
+	return save("Save", configHomeWritableFS, name, v)
+}
 
-	configHome, err := ConfigHome()
+// LoadState loads the state file for the specified name into the value
+// pointed to by v.
+//
+// State files hold the data that a program should keep between restarts, but
+// that is not configuration, like the history of actions, or the layout of
+// windows. They are searched by name in StateHome() only, in the same way as
+// Open does, and are decoded with the options of SetOptions.
+//
+// It is not an error for the state file to not exist; v is then left
+// untouched, unless the options populate it, for instance from the
+// environment.
+func LoadState(name string, v interface{}) error {
+	checkNames(name)
+	home, err := StateHome()
 	if err != nil {
 		return err
 	}
-	path := filepath.Join(configHome, name)
+	return stateLoader(home).Files(name).Load(v)
+}
 
-	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil && !errors.Is(err, os.ErrExist) {
+// SaveState saves the specified value in v into a named state file.
+//
+// The name is interpreted relative to the return value of StateHome(), and
+// the configuration language is deduced from its extension, in the same way
+// as Save does.
+func SaveState(name string, v interface{}) error {
+	return save("SaveState", stateHomeWritableFS, name, v)
+}
+
+func save(fn string, home func() (WritableFS, error), name string, v interface{}) error {
+	checkSaveName(fn, name)
+	fsys, err := home()
+	if err != nil {
 		return err
 	}
-	f, err := os.Create(path)
+	loader := defaultLoader()
+	newEncoder, opts, err := loader.fileEncoder(name)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
+	return writeFile(fsys, name, opts.Backups, opts.Lock, func(w io.Writer) error {
+		return newEncoder(w).Option(loader.encoderOptions...).Encode(v)
+	})
+}
 
-	return NewEncoder(f).Encode(v)
+// writeFile replaces the named file of fsys with the contents written by
+// write, keeping the specified number of backups. The files of directories
+// are replaced atomically, while holding an advisory lock on them if lock is
+// true.
+func writeFile(fsys WritableFS, name string, backups int, lock bool, write func(io.Writer) error) error {
+	dir, ok := fsys.(dirFS)
+	if !ok {
+		return writeFS(fsys, filepath.ToSlash(name), backups, write)
+	}
+
+	path := filepath.Join(dir.Dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil && !errors.Is(err, os.ErrExist) {
+		return err
+	}
+	if lock {
+		unlock, err := fsutil.Lock(path)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+	return fsutil.WriteFile(path, backups, write)
+}
+
+func checkSaveName(fn, name string) {
+	if filepath.IsAbs(name) {
+		panic(fn + " does not take absolute paths; use os.Create and NewEncoder instead.")
+	}
+	if strings.HasPrefix(name, "."+string(filepath.Separator)) || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		panic(fn + " does not take cwd-relative paths; use os.Create and NewEncoder instead.")
+	}
 }
@@ -0,0 +1,87 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package dotenv
+
+import (
+	"context"
+	"io"
+	"os"
+	"reflect"
+
+	. "snai.pe/boa/syntax"
+
+	"snai.pe/boa/encoding"
+	"snai.pe/boa/internal/encutil"
+	"snai.pe/boa/internal/reflectutil"
+)
+
+type unmarshaler struct {
+	encutil.UnmarshalerBase
+	encutil.StructTagParser
+}
+
+// UnmarshalValue converts the string values of a dotenv document into the
+// type of the destination value, like environment variables are.
+func (unmarshaler) UnmarshalValue(val reflect.Value, node Value) (bool, error) {
+	return reflectutil.UnmarshalString(val, node)
+}
+
+var (
+	_ reflectutil.StructTagParser = (*unmarshaler)(nil)
+	_ reflectutil.Unmarshaler     = (*unmarshaler)(nil)
+)
+
+type decoder struct {
+	in          io.Reader
+	unmarshaler unmarshaler
+}
+
+// NewDecoder returns a decoder for dotenv documents, which hold flat
+// configurations as KEY=value lines.
+//
+// Variable references in values are expanded with the variables that are
+// defined earlier in the document, or else with the environment variables
+// that are looked up with the LookupEnv decoder option.
+func NewDecoder(rd io.Reader) encoding.Decoder {
+	var decoder decoder
+	decoder.in = rd
+	decoder.unmarshaler.NewParser = func(ctx context.Context, in io.Reader) Parser {
+		lookup := decoder.unmarshaler.LookupEnv
+		if lookup == nil {
+			lookup = os.LookupEnv
+		}
+		return newParser(ctx, in, lookup)
+	}
+	decoder.unmarshaler.Self = &decoder.unmarshaler
+	decoder.unmarshaler.StructTagParser = encutil.StructTagParser{Tag: "dotenv"}
+	decoder.unmarshaler.Extensions = []string{".env"}
+
+	// Defaults
+	decoder.unmarshaler.NamingConvention = encoding.ScreamingSnakeCase
+	return &decoder
+}
+
+func (decoder *decoder) Option(opts ...interface{}) encoding.Decoder {
+	if err := decoder.unmarshaler.Option(opts...); err != nil {
+		panic(err)
+	}
+	return decoder
+}
+
+func (decoder *decoder) Decode(v interface{}) error {
+	return decoder.unmarshaler.Decode(decoder.in, v)
+}
+
+// Load is a convenience function to load a dotenv document into the value
+// pointed at by v. It is functionally equivalent to NewDecoder(<file at path>).Decode(v).
+func Load(path string, v interface{}) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return NewDecoder(f).Decode(v)
+}
@@ -0,0 +1,218 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package dotenv
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"snai.pe/boa/syntax"
+)
+
+const document = `# Service configuration
+export HOST=example.com
+PORT = 8080   # inline comment
+GREETING="hello\tworld\n"
+LITERAL='$HOST is not expanded'
+MULTILINE="first
+second"
+URL=http://${HOST}:$PORT/path#fragment
+HOME_DIR=${HOME}
+FALLBACK=${UNSET:-default}
+EMPTY=
+ESCAPED="\$HOST"
+`
+
+func TestRead(t *testing.T) {
+	lookup := func(key string) (string, bool) {
+		if key == "HOME" {
+			return "/home/user", true
+		}
+		return "", false
+	}
+
+	vars, err := Read(strings.NewReader(document), lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"HOST":      "example.com",
+		"PORT":      "8080",
+		"GREETING":  "hello\tworld\n",
+		"LITERAL":   "$HOST is not expanded",
+		"MULTILINE": "first\nsecond",
+		"URL":       "http://example.com:8080/path#fragment",
+		"HOME_DIR":  "/home/user",
+		"FALLBACK":  "default",
+		"EMPTY":     "",
+		"ESCAPED":   "$HOST",
+	}
+	if !reflect.DeepEqual(vars, expected) {
+		t.Fatalf("expected %q, got %q", expected, vars)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tcases := []string{
+		"KEY",
+		"KEY=\"unterminated",
+		"KEY=${UNTERMINATED",
+		"KEY=value\n!",
+		"KEY='value' trailing",
+	}
+	for _, tcase := range tcases {
+		if _, err := Read(strings.NewReader(tcase), nil); err == nil {
+			t.Errorf("%q: expected error", tcase)
+		}
+	}
+}
+
+type config struct {
+	Host      string
+	Port      int
+	Debug     bool
+	Ratio     *float64
+	Greeting  string `help:"The greeting message."`
+	Ignored   string `dotenv:"-"`
+	Renamed   string `dotenv:"OTHER_NAME"`
+	Interface interface{}
+}
+
+func TestDecode(t *testing.T) {
+	in := `
+HOST=localhost
+PORT=8080
+DEBUG=true
+RATIO=
+GREETING="hello, ${HOST}"
+OTHER_NAME=renamed
+INTERFACE=value
+`
+	ratio := 0.5
+	cfg := config{Ratio: &ratio}
+	if err := NewDecoder(strings.NewReader(in)).Decode(&cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := config{
+		Host:      "localhost",
+		Port:      8080,
+		Debug:     true,
+		Greeting:  "hello, localhost",
+		Renamed:   "renamed",
+		Interface: "value",
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, cfg)
+	}
+
+	if err := NewDecoder(strings.NewReader("PORT=http")).Decode(&cfg); err == nil {
+		t.Fatal("expected error when decoding a non-numeric port")
+	}
+}
+
+func TestEncode(t *testing.T) {
+	ratio := 0.5
+	cfg := config{
+		Host:      "localhost",
+		Port:      8080,
+		Ratio:     &ratio,
+		Greeting:  "hello \"$USER\"\n",
+		Interface: "value",
+	}
+
+	var out bytes.Buffer
+	if err := NewEncoder(&out).Encode(&cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `HOST=localhost
+PORT=8080
+DEBUG=false
+RATIO=0.5
+# The greeting message.
+GREETING="hello \"\$USER\"\n"
+OTHER_NAME=""
+INTERFACE=value
+`
+	if out.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	var decoded config
+	if err := NewDecoder(&out).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, cfg) {
+		t.Fatalf("expected %+v, got %+v", cfg, decoded)
+	}
+
+	nested := struct{ Sub struct{ Key string } }{}
+	if err := NewEncoder(&out).Encode(&nested); err == nil {
+		t.Fatal("expected error when encoding a nested struct")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	var doc *syntax.Document
+	if err := NewDecoder(strings.NewReader(document)).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := NewEncoder(&out).Encode(doc); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != document {
+		t.Fatalf("expected:\n%s\ngot:\n%s", document, out.String())
+	}
+}
+
+func TestLookupEnv(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	t.Setenv("DOTENV_TEST_REAL", "real")
+	t.Setenv("DOTENV_TEST_OVERRIDDEN", "real")
+
+	base := write(".env", "DOTENV_TEST_OVERRIDDEN=base\nDOTENV_TEST_BASE=base\nDOTENV_TEST_LOCAL=base\n")
+	local := write(".env.local", "DOTENV_TEST_LOCAL=${DOTENV_TEST_BASE}-local\n")
+
+	lookup, err := LookupEnv(base, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"DOTENV_TEST_REAL":       "real",
+		"DOTENV_TEST_OVERRIDDEN": "base",
+		"DOTENV_TEST_BASE":       "base",
+		"DOTENV_TEST_LOCAL":      "base-local",
+	}
+	for k, v := range expected {
+		if actual, ok := lookup(k); !ok || actual != v {
+			t.Errorf("%s: expected %q, got %q (defined: %v)", k, v, actual, ok)
+		}
+	}
+	if _, ok := lookup("DOTENV_TEST_UNDEFINED"); ok {
+		t.Error("DOTENV_TEST_UNDEFINED: expected undefined variable")
+	}
+
+	if _, err := LookupEnv(filepath.Join(dir, "nonexistent")); !os.IsNotExist(err) {
+		t.Errorf("expected not-exist error, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package dotenv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+
+	"snai.pe/boa/encoding"
+	"snai.pe/boa/internal/encutil"
+	"snai.pe/boa/internal/fsutil"
+	"snai.pe/boa/internal/reflectutil"
+	"snai.pe/boa/syntax"
+)
+
+type encoder struct {
+	marshaler marshaler
+}
+
+// NewEncoder returns an encoder that writes flat configurations as dotenv
+// documents. Nested maps, structs, and lists cannot be encoded.
+func NewEncoder(out io.Writer) encoding.Encoder {
+	var encoder encoder
+	encoder.marshaler.Writer = out
+	encoder.marshaler.Self = &encoder.marshaler
+	encoder.marshaler.StructTagParser = encutil.StructTagParser{Tag: "dotenv"}
+	encoder.marshaler.CommentPrefix = "# "
+
+	// Defaults
+	encoder.marshaler.NamingConvention = encoding.ScreamingSnakeCase
+	return &encoder
+}
+
+func (encoder *encoder) Encode(v interface{}) error {
+	encoder.marshaler.depth = 0
+	return encoder.marshaler.Encode(v)
+}
+
+func (encoder *encoder) Option(opts ...interface{}) encoding.Encoder {
+	if err := encoder.marshaler.Option(nil, opts...); err != nil {
+		panic(err)
+	}
+	return encoder
+}
+
+type marshaler struct {
+	encutil.MarshalerBase
+	encutil.StructTagParser
+
+	// state
+	depth int
+}
+
+func (m *marshaler) MarshalValue(v reflect.Value) (bool, error) {
+	return false, nil
+}
+
+func (m *marshaler) MarshalList(v reflect.Value) (bool, error) {
+	return false, fmt.Errorf("dotenv: cannot encode list %v in a flat document", v.Type())
+}
+
+func (m *marshaler) MarshalListElem(l, v reflect.Value, i int) (bool, error) {
+	return false, nil
+}
+
+func (m *marshaler) MarshalMap(v reflect.Value, kvs []reflectutil.MapEntry) (bool, error) {
+	if m.depth > 0 {
+		return false, fmt.Errorf("dotenv: cannot encode nested %v in a flat document", v.Type())
+	}
+	m.depth++
+	return false, nil
+}
+
+func (m *marshaler) MarshalMapPost(v reflect.Value, kvs []reflectutil.MapEntry) error {
+	m.depth--
+	return nil
+}
+
+func (m *marshaler) MarshalMapKey(mv reflect.Value, kv reflectutil.MapEntry, i int) error {
+	if err := m.WriteComment("# ", kv.Options.Help, 0); err != nil {
+		return err
+	}
+	if err := m.WriteString(kv.Key); err != nil {
+		return err
+	}
+	return m.WriteString("=")
+}
+
+func (m *marshaler) MarshalMapValue(mv reflect.Value, kv reflectutil.MapEntry, i int) (bool, error) {
+	return false, nil
+}
+
+func (m *marshaler) MarshalMapValuePost(mv reflect.Value, kv reflectutil.MapEntry, i int) error {
+	return m.WriteNewline()
+}
+
+func (m *marshaler) MarshalStructValuePost(mv reflect.Value, kv reflectutil.MapEntry, i int) error {
+	return m.WriteNewline()
+}
+
+func isBareChar(r rune) bool {
+	return isNameChar(r) || strings.ContainsRune("-./:,@+%=~", r)
+}
+
+func (m *marshaler) MarshalString(s string) error {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool { return !isBareChar(r) }) == -1 {
+		return m.WriteString(s)
+	}
+
+	var out strings.Builder
+	out.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\', '$':
+			out.WriteByte('\\')
+			out.WriteRune(r)
+		case '\n':
+			out.WriteString(`\n`)
+		case '\r':
+			out.WriteString(`\r`)
+		case '\t':
+			out.WriteString(`\t`)
+		default:
+			out.WriteRune(r)
+		}
+	}
+	out.WriteByte('"')
+	return m.WriteString(out.String())
+}
+
+func (m *marshaler) MarshalNaN(v float64) error {
+	return m.WriteString("NaN")
+}
+
+func (m *marshaler) MarshalInf(v float64) error {
+	if math.IsInf(v, -1) {
+		return m.WriteString("-Inf")
+	}
+	return m.WriteString("+Inf")
+}
+
+func (m *marshaler) MarshalNode(node syntax.Value) error {
+	for _, tok := range node.Base().Tokens {
+		if err := m.WriteString(tok.Raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *marshaler) MarshalNodePost(node syntax.Value) error {
+	for _, tok := range node.Base().Suffix {
+		if err := m.WriteString(tok.Raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	_ reflectutil.Marshaler                = (*marshaler)(nil)
+	_ reflectutil.PostMapMarshaler         = (*marshaler)(nil)
+	_ reflectutil.PostMapValueMarshaler    = (*marshaler)(nil)
+	_ reflectutil.PostStructValueMarshaler = (*marshaler)(nil)
+	_ reflectutil.StructTagParser          = (*marshaler)(nil)
+	_ reflectutil.NaNMarshaler             = (*marshaler)(nil)
+	_ reflectutil.InfMarshaler             = (*marshaler)(nil)
+)
+
+func Marshal(v interface{}) ([]byte, error) {
+	var out bytes.Buffer
+	if err := NewEncoder(&out).Encode(v); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// Save is a convenience function to save the value pointed at by v into a
+// dotenv document at path. It is functionally equivalent to NewEncoder(<file at path>).Encode(v),
+// except that the file is replaced atomically, with its mode preserved.
+func Save(path string, v interface{}) error {
+	return fsutil.WriteFile(path, 0, func(w io.Writer) error {
+		return NewEncoder(w).Encode(v)
+	})
+}
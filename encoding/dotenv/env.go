@@ -0,0 +1,71 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package dotenv
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"snai.pe/boa/syntax"
+)
+
+// Read parses the dotenv document in, and returns the variables that it
+// defines. Variable references are expanded with the variables that are
+// defined earlier in the document, or else with lookup, if non-nil.
+func Read(in io.Reader, lookup func(string) (string, bool)) (map[string]string, error) {
+	doc, err := newParser(context.Background(), in, lookup).Parse()
+	if err != nil {
+		return nil, err
+	}
+	root := doc.Root.(*syntax.Map)
+	vars := make(map[string]string, len(root.Entries))
+	for _, entry := range root.Entries {
+		vars[entry.Key.(*syntax.String).Value] = entry.Value.(*syntax.String).Value
+	}
+	return vars, nil
+}
+
+// LookupEnv reads the dotenv files at the specified paths, and returns a
+// function that looks up environment variables in them, and then in the
+// real environment. Variables of later files override the ones of earlier
+// files, and variable references in each file are expanded the same way.
+//
+// The returned function can be passed to boa.EnvironFunc:
+//
+//	lookup, err := dotenv.LookupEnv(".env", ".env.local")
+//	if err != nil {
+//		return err
+//	}
+//	boa.SetOptions(boa.EnvironFunc(lookup))
+func LookupEnv(paths ...string) (func(string) (string, bool), error) {
+	vars := make(map[string]string)
+	lookup := func(key string) (string, bool) {
+		if v, ok := vars[key]; ok {
+			return v, true
+		}
+		return os.LookupEnv(key)
+	}
+
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		fvars, err := Read(f, lookup)
+		f.Close()
+		if err != nil {
+			if e, ok := err.(*syntax.Error); ok {
+				e.Filename = path
+			}
+			return nil, err
+		}
+		for k, v := range fvars {
+			vars[k] = v
+		}
+	}
+	return lookup, nil
+}
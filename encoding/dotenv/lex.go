@@ -0,0 +1,301 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package dotenv
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	. "snai.pe/boa/syntax"
+)
+
+const (
+	TokenEqual TokenType = "'='"
+)
+
+type lexerState struct {
+	blankLine bool
+
+	// key is the last variable name that was read, and vars holds the values
+	// of the variables defined so far, for expansion.
+	key    string
+	vars   map[string]string
+	lookup func(string) (string, bool)
+}
+
+type pooledLexer struct {
+	lx    Lexer
+	state lexerState
+	done  func()
+}
+
+var lexerPool sync.Pool
+
+func init() {
+	lexerPool.New = func() any {
+		p := new(pooledLexer)
+		p.done = func() { lexerPool.Put(p) }
+		return p
+	}
+}
+
+// newLexer returns a lexer for the dotenv document in input. Variables that
+// are referenced in values, and that are not defined earlier in the document,
+// are expanded with lookup; if lookup is nil, they expand to the empty string.
+func newLexer(ctx context.Context, input io.Reader, lookup func(string) (string, bool)) *Lexer {
+	p := lexerPool.Get().(*pooledLexer)
+	p.state = lexerState{
+		blankLine: true,
+		vars:      make(map[string]string),
+		lookup:    lookup,
+	}
+	p.lx.Done = p.done
+	p.lx.Reinit(ctx, input, p.state.lex)
+	return &p.lx
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\v' || r == '\f'
+}
+
+func isNameStart(r rune) bool {
+	return r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+func isNameChar(r rune) bool {
+	return isNameStart(r) || r >= '0' && r <= '9'
+}
+
+func isKeyChar(r rune) bool {
+	return isNameChar(r) || r == '.' || r == '-'
+}
+
+func (state *lexerState) lex(l *Lexer) StateFunc {
+	r, _, err := l.ReadRune()
+	if err != nil {
+		return l.Error(err)
+	}
+
+	switch {
+	case r == '\n':
+		l.Emit(TokenNewline, nil)
+		state.blankLine = true
+	case r == '\r':
+		if err := l.RequireLF(); err != nil {
+			return l.Error(err)
+		}
+		l.Emit(TokenNewline, nil)
+		state.blankLine = true
+	case isSpace(r):
+		if _, err := l.AcceptWhile(isSpace); err != nil {
+			return l.Error(err)
+		}
+		l.Emit(TokenWhitespace, nil)
+	case r == '#':
+		comment, err := l.AcceptWhile(func(r rune) bool {
+			return r != '\n' && r != '\r'
+		})
+		if err != nil {
+			return l.Error(err)
+		}
+		typ := TokenComment
+		if !state.blankLine {
+			typ = TokenInlineComment
+		}
+		l.Emit(typ, strings.TrimSpace(comment))
+	case r == '=':
+		l.Emit(TokenEqual, nil)
+		state.blankLine = false
+		return state.lexValue
+	case isKeyChar(r):
+		key, err := l.AcceptWhile(isKeyChar)
+		if err != nil {
+			return l.Error(err)
+		}
+		state.key = string(r) + key
+		l.Emit(TokenIdentifier, state.key)
+		state.blankLine = false
+	default:
+		return l.Errorf("unexpected character %q", r)
+	}
+	return state.lex
+}
+
+func (state *lexerState) lexValue(l *Lexer) StateFunc {
+	ws, err := l.AcceptWhile(isSpace)
+	if err != nil {
+		return l.Error(err)
+	}
+	if ws != "" {
+		l.Emit(TokenWhitespace, nil)
+	}
+
+	r, _, err := l.ReadRune()
+	switch {
+	case err == io.EOF:
+		state.emitValue(l, "")
+		return l.Error(err)
+	case err != nil:
+		return l.Error(err)
+	}
+
+	switch {
+	case r == '"' || r == '\'':
+		return state.lexQuoted(l, r)
+	case r == '\n' || r == '\r' || r == '#' && ws != "":
+		l.UnreadRune()
+		state.emitValue(l, "")
+		return state.lex
+	}
+
+	// Unquoted values run until the end of the line, or until a comment,
+	// which must be preceded by whitespace. Trailing whitespace is part of
+	// the token, but not of the value.
+	prev := r
+	for {
+		r, _, err := l.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return l.Error(err)
+		}
+		if r == '\n' || r == '\r' || r == '#' && isSpace(prev) {
+			l.UnreadRune()
+			break
+		}
+		prev = r
+	}
+
+	value, err := state.expand(strings.TrimRight(l.Token(), " \t\v\f"), false)
+	if err != nil {
+		return l.Error(err)
+	}
+	state.emitValue(l, value)
+	return state.lex
+}
+
+func (state *lexerState) lexQuoted(l *Lexer, delim rune) StateFunc {
+	escaped := false
+	for {
+		r, _, err := l.ReadRune()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return l.Errorf("reading quoted value: %w", err)
+		}
+		if escaped {
+			escaped = false
+			continue
+		}
+		if r == '\\' && delim == '"' {
+			escaped = true
+			continue
+		}
+		if r == delim {
+			break
+		}
+	}
+
+	tok := l.Token()
+	content := tok[1 : len(tok)-1]
+
+	if delim == '\'' {
+		state.emitValue(l, content)
+		return state.lex
+	}
+
+	value, err := state.expand(content, true)
+	if err != nil {
+		return l.Error(err)
+	}
+	state.emitValue(l, value)
+	return state.lex
+}
+
+func (state *lexerState) emitValue(l *Lexer, value string) {
+	state.vars[state.key] = value
+	l.Emit(TokenString, value)
+}
+
+func (state *lexerState) get(name string) (string, bool) {
+	if v, ok := state.vars[name]; ok {
+		return v, true
+	}
+	if state.lookup != nil {
+		return state.lookup(name)
+	}
+	return "", false
+}
+
+// expand substitutes the variable references of s, in the $NAME, ${NAME},
+// ${NAME:-default} and ${NAME-default} forms. If escapes is true, backslash
+// escape sequences are also interpreted, as in double-quoted values.
+func (state *lexerState) expand(s string, escapes bool) (string, error) {
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && escapes && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				out.WriteByte('\n')
+			case 'r':
+				out.WriteByte('\r')
+			case 't':
+				out.WriteByte('\t')
+			case '"', '\\', '$':
+				out.WriteByte(s[i])
+			default:
+				out.WriteByte('\\')
+				out.WriteByte(s[i])
+			}
+
+		case c == '$' && i+1 < len(s) && s[i+1] == '{':
+			end := strings.IndexByte(s[i+2:], '}')
+			if end == -1 {
+				return "", fmt.Errorf("unterminated variable reference %q", s[i:])
+			}
+			ref := s[i+2 : i+2+end]
+			i += end + 2
+
+			name, def, hasDef := ref, "", false
+			unsetOnly := true
+			if j := strings.Index(ref, ":-"); j != -1 {
+				name, def, hasDef, unsetOnly = ref[:j], ref[j+2:], true, false
+			} else if j := strings.IndexByte(ref, '-'); j != -1 {
+				name, def, hasDef = ref[:j], ref[j+1:], true
+			}
+			if name == "" || !isNameStart(rune(name[0])) || strings.IndexFunc(name, func(r rune) bool { return !isNameChar(r) }) != -1 {
+				return "", fmt.Errorf("bad variable reference ${%s}", ref)
+			}
+
+			val, ok := state.get(name)
+			if hasDef && (!ok || !unsetOnly && val == "") {
+				val = def
+			}
+			out.WriteString(val)
+
+		case c == '$' && i+1 < len(s) && isNameStart(rune(s[i+1])):
+			j := i + 1
+			for j < len(s) && isNameChar(rune(s[j])) {
+				j++
+			}
+			val, _ := state.get(s[i+1 : j])
+			out.WriteString(val)
+			i = j - 1
+
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package dotenv
+
+import (
+	"context"
+	"io"
+
+	. "snai.pe/boa/syntax"
+)
+
+type parser struct {
+	ParserBase
+}
+
+func newParser(ctx context.Context, in io.Reader, lookup func(string) (string, bool)) Parser {
+	p := parser{
+		ParserBase: ParserBase{Lexer: newLexer(ctx, in, lookup)},
+	}
+	return &p
+}
+
+func (p *parser) Next(tokens *[]Token) Token {
+	return p.Skip(tokens, TokenComment, TokenInlineComment, TokenNewline, TokenWhitespace)
+}
+
+func (p *parser) Parse() (doc *Document, err error) {
+	defer Recover(&err)
+	return p.document(), nil
+}
+
+func (p *parser) document() *Document {
+	root := &Map{}
+	doc := &Document{Root: root}
+
+	for {
+		leading := make([]Token, 0, 4)
+		tok := p.Next(&leading)
+		if tok.Type == TokenEOF {
+			root.Suffix = leading
+			return doc
+		}
+		p.Back(tok)
+		root.Entries = append(root.Entries, p.entry(leading))
+	}
+}
+
+func (p *parser) entry(leading []Token) *MapEntry {
+	name := p.RawNext()
+	if name.Type != TokenIdentifier {
+		p.Fail(name, UnexpectedTokenError{TokenIdentifier})
+	}
+	key := &String{
+		Node: Node{
+			Tokens:   append(leading, name),
+			Position: name.Start,
+		},
+		Value: name.Value.(string),
+	}
+
+	// Skip the optional `export` keyword.
+	tok := p.Skip(&key.Tokens, TokenWhitespace)
+	if key.Value == "export" && tok.Type == TokenIdentifier && key.Tokens[len(key.Tokens)-1].Type == TokenWhitespace {
+		key.Tokens = append(key.Tokens, tok)
+		key.Position = tok.Start
+		key.Value = tok.Value.(string)
+		tok = p.Skip(&key.Tokens, TokenWhitespace)
+	}
+	if tok.Type != TokenEqual {
+		p.Fail(tok, UnexpectedTokenError{TokenEqual})
+	}
+	key.Tokens = append(key.Tokens, tok)
+
+	value := &String{}
+	tok = p.Skip(&value.Tokens, TokenWhitespace)
+	if tok.Type != TokenString {
+		p.Fail(tok, UnexpectedTokenError{TokenString})
+	}
+	value.Tokens = append(value.Tokens, tok)
+	value.Position = tok.Start
+	value.Value = tok.Value.(string)
+
+	// The rest of the line, up to and including the newline, belongs to
+	// the value.
+	tok = p.Skip(&value.Suffix, TokenWhitespace, TokenInlineComment)
+	switch tok.Type {
+	case TokenNewline:
+		value.Suffix = append(value.Suffix, tok)
+	case TokenEOF:
+		p.Back(tok)
+	default:
+		p.Fail(tok, UnexpectedTokenError{TokenNewline})
+	}
+
+	return &MapEntry{Key: key, Value: value}
+}
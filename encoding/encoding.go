@@ -52,6 +52,12 @@ func (e *LoadError) Unwrap() error {
 // in the package of the relevant encoder or decoder.
 type CommonOptions struct {
 	NamingConvention NamingConvention
+
+	// EnvNamingConvention and EnvSeparator control how environment variable
+	// names are formed from configuration keys. They are common options,
+	// since encoders document environment variables in templates.
+	EnvNamingConvention NamingConvention
+	EnvSeparator        string
 }
 
 // CommonOption represents an option common to all encoders and decoders in boa.
@@ -66,6 +72,23 @@ type CommonOption func(*CommonOptions)
 type EncoderOptions struct {
 	Indent    string
 	LineBreak string
+
+	// Template, if true, makes the encoder write a configuration template,
+	// in which every field is annotated with its type, default, and
+	// environment variables, and optional fields are commented out.
+	Template bool
+
+	// EnvPrefix is the prefix of the environment variables that are
+	// documented in templates.
+	EnvPrefix string
+
+	// Backups is the number of backups that Save keeps of the files it
+	// replaces.
+	Backups int
+
+	// Lock, if true, makes Save take an advisory lock on the files it
+	// replaces.
+	Lock bool
 }
 
 // EncoderOption represents an option common to all encoders in boa.
@@ -83,6 +106,41 @@ type DecoderOptions struct {
 	EnvPrefix    string
 	LookupEnv    func(string) (string, bool)
 
+	// ListEnv is the enumerating counterpart of LookupEnv, and returns all
+	// environment variables in key=value form. It is only used when
+	// EnvCollections is set.
+	ListEnv func() []string
+
+	// EnvCollections, if true, enables the creation of list elements and
+	// map entries from the environment variables that are nested under
+	// lists and maps.
+	EnvCollections bool
+
+	// EnvFormat, if non-empty, is the file extension of the configuration
+	// language of the lists, maps, and structs that are written inline in
+	// environment variables.
+	EnvFormat string
+
+	// EnvDecoder creates the decoder of the EnvFormat language. It is
+	// resolved from the languages known to the decoder.
+	EnvDecoder func(io.Reader) Decoder
+
+	// ListSeparator separates the elements of lists that are set from a
+	// single string, in environment variables or configuration files. The
+	// `sep` tag of struct fields overrides it for their lists. Defaults to
+	// os.PathListSeparator for environment variables; strings of
+	// configuration files are not split if it is empty.
+	ListSeparator string
+
+	// Overrides are documents whose values are applied after the
+	// configuration files and the environment, in order.
+	Overrides []*syntax.Document
+
+	// Format is the file extension of the configuration language of the
+	// inputs whose name has no known extension, such as ".toml". If empty,
+	// the language is detected from their contents.
+	Format string
+
 	// Context, if non-nil, scopes the decoding operation. When the
 	// context is cancelled or times out, the decoder returns ctx.Err().
 	Context context.Context
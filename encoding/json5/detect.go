@@ -0,0 +1,48 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package json5
+
+import (
+	"bytes"
+	"context"
+
+	"snai.pe/boa/internal/encutil"
+	. "snai.pe/boa/syntax"
+)
+
+// Detect reports whether data looks like the start of a JSON5 document, which
+// is an object or an array, based on its first tokens.
+func Detect(data []byte) bool {
+	tokens := encutil.FirstTokens(newLexer(context.Background(), bytes.NewReader(data)), 16)
+	n, ok := detectValue(tokens, 0)
+	return ok && n > 0 && (tokens[0].Type == TokenLBrace || tokens[0].Type == TokenLSquare)
+}
+
+// detectValue reports whether a value starts at index i of tokens, and returns
+// the index of the first token that it does not check. Running out of tokens
+// is not a mismatch, since data may be truncated.
+func detectValue(tokens []Token, i int) (int, bool) {
+	switch encutil.TokenAt(tokens, i) {
+	case TokenEOF:
+		return i, true
+	case TokenString, TokenNumber, TokenBool, TokenNil, TokenPlus, TokenMinus:
+		return i + 1, true
+	case TokenLBrace:
+		switch encutil.TokenAt(tokens, i+1) {
+		case TokenRBrace, TokenEOF:
+			return i + 2, true
+		case TokenIdentifier, TokenString:
+			typ := encutil.TokenAt(tokens, i+2)
+			return i + 3, typ == TokenColon || typ == TokenEOF
+		}
+	case TokenLSquare:
+		if typ := encutil.TokenAt(tokens, i+1); typ == TokenRSquare || typ == TokenEOF {
+			return i + 2, true
+		}
+		return detectValue(tokens, i+1)
+	}
+	return i, false
+}
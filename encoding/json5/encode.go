@@ -11,13 +11,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"os"
 	"reflect"
 	"strings"
 	"unicode/utf8"
 
 	"snai.pe/boa/encoding"
 	"snai.pe/boa/internal/encutil"
+	"snai.pe/boa/internal/fsutil"
 	"snai.pe/boa/internal/reflectutil"
 	"snai.pe/boa/syntax"
 )
@@ -31,6 +31,7 @@ func NewEncoder(out io.Writer) encoding.Encoder {
 	encoder.marshaler.Writer = out
 	encoder.marshaler.Self = &encoder.marshaler
 	encoder.marshaler.StructTagParser = encutil.StructTagParser{Tag: "json"}
+	encoder.marshaler.CommentPrefix = "// "
 
 	// Defaults
 	encoder.marshaler.Indent = "  "
@@ -487,15 +488,10 @@ func Prefix(prefix string) EncoderOption {
 }
 
 // Save is a convenience function to save the value pointed at by v into a
-// JSON5 document at path. It is functionally equivalent to NewEncoder(<file at path>).Encode(v).
+// JSON5 document at path. It is functionally equivalent to NewEncoder(<file at path>).Encode(v),
+// except that the file is replaced atomically, with its mode preserved.
 func Save(path string, v interface{}) error {
-	f, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	if err := NewEncoder(f).Encode(v); err != nil {
-		return err
-	}
-	return f.Close()
+	return fsutil.WriteFile(path, 0, func(w io.Writer) error {
+		return NewEncoder(w).Encode(v)
+	})
 }
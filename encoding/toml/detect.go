@@ -0,0 +1,53 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package toml
+
+import (
+	"bytes"
+	"context"
+
+	"snai.pe/boa/internal/encutil"
+	. "snai.pe/boa/syntax"
+)
+
+// Detect reports whether data looks like the start of a TOML document, which
+// is a table header or a key/value pair, based on its first tokens.
+func Detect(data []byte) bool {
+	tokens := encutil.FirstTokens(newLexer(context.Background(), bytes.NewReader(data)), 16)
+	if len(tokens) == 0 {
+		return false
+	}
+
+	i := 0
+	end := TokenEqual
+	switch tokens[0].Type {
+	case TokenLSquare:
+		i, end = 1, TokenRSquare
+	case TokenDoubleLSquare:
+		i, end = 1, TokenDoubleRSquare
+	}
+
+	// Dotted keys. Bare keys made of digits are lexed as numbers.
+	for {
+		switch encutil.TokenAt(tokens, i) {
+		case TokenIdentifier, TokenString, TokenNumber, TokenBool:
+		case TokenEOF:
+			return i > 0
+		default:
+			return false
+		}
+		switch encutil.TokenAt(tokens, i+1) {
+		case TokenDot:
+			i += 2
+		case end:
+			return true
+		case TokenEOF:
+			return i > 0
+		default:
+			return false
+		}
+	}
+}
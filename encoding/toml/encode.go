@@ -9,7 +9,6 @@ import (
 	"bytes"
 	"fmt"
 	"io"
-	"os"
 	"reflect"
 	"sort"
 	"strings"
@@ -17,6 +16,7 @@ import (
 
 	"snai.pe/boa/encoding"
 	"snai.pe/boa/internal/encutil"
+	"snai.pe/boa/internal/fsutil"
 	"snai.pe/boa/internal/reflectutil"
 	"snai.pe/boa/syntax"
 )
@@ -35,6 +35,7 @@ func NewEncoder(out io.Writer) encoding.Encoder {
 	encoder.marshaler.Writer = out
 	encoder.marshaler.Self = &encoder.marshaler
 	encoder.marshaler.StructTagParser = encutil.StructTagParser{Tag: "toml"}
+	encoder.marshaler.CommentPrefix = "# "
 
 	// Defaults
 	encoder.marshaler.Indent = "  "
@@ -643,15 +644,10 @@ func Marshal(v interface{}) ([]byte, error) {
 }
 
 // Save is a convenience function to save the value pointed at by v into a
-// TOML document at path. It is functionally equivalent to NewEncoder(<file at path>).Encode(v).
+// TOML document at path. It is functionally equivalent to NewEncoder(<file at path>).Encode(v),
+// except that the file is replaced atomically, with its mode preserved.
 func Save(path string, v interface{}) error {
-	f, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	if err := NewEncoder(f).Encode(v); err != nil {
-		return err
-	}
-	return f.Close()
+	return fsutil.WriteFile(path, 0, func(w io.Writer) error {
+		return NewEncoder(w).Encode(v)
+	})
 }
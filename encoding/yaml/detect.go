@@ -0,0 +1,32 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package yaml
+
+import (
+	"bytes"
+	"context"
+
+	"snai.pe/boa/internal/encutil"
+	. "snai.pe/boa/syntax"
+)
+
+// Detect reports whether data looks like the start of a YAML document, which
+// is a mapping or a sequence, based on its first tokens.
+//
+// Flow collections are not detected, since they are also the start of JSON
+// documents.
+func Detect(data []byte) bool {
+	l, _, done := newLexer(context.Background(), bytes.NewReader(data))
+	defer done()
+	tokens := encutil.FirstTokens(l, 16, TokenIndent, TokenTag, TokenAnchor)
+	switch encutil.TokenAt(tokens, 0) {
+	case TokenDirective, TokenDirectivesEnd, TokenQuery, TokenDash:
+		return true
+	case TokenScalar, TokenString:
+		return encutil.TokenAt(tokens, 1) == TokenColon
+	}
+	return false
+}
@@ -8,12 +8,12 @@ package yaml
 import (
 	"bytes"
 	"io"
-	"os"
 	"reflect"
 	"strings"
 
 	"snai.pe/boa/encoding"
 	"snai.pe/boa/internal/encutil"
+	"snai.pe/boa/internal/fsutil"
 	"snai.pe/boa/internal/reflectutil"
 	. "snai.pe/boa/syntax"
 )
@@ -43,6 +43,7 @@ func NewEncoder(out io.Writer) encoding.Encoder {
 	enc.marshaler.Writer = enc.marshaler.tracker
 	enc.marshaler.Self = &enc.marshaler
 	enc.marshaler.StructTagParser = encutil.StructTagParser{Tag: "yaml"}
+	enc.marshaler.CommentPrefix = "# "
 	enc.marshaler.Indent = "  "
 	enc.marshaler.NamingConvention = encoding.KebabCase
 	return &enc
@@ -70,9 +71,8 @@ type marshaler struct {
 	encutil.MarshalerBase
 	encutil.StructTagParser
 
-	tracker   *newlineTracker
-	depth     int
-	inlineVal bool // true when the current map value is a scalar (inline after ": ")
+	tracker *newlineTracker
+	depth   int
 }
 
 // isCompound reports whether v is a non-empty collection that should be
@@ -223,7 +223,6 @@ func (m *marshaler) MarshalMapKey(mv reflect.Value, kv reflectutil.MapEntry, i i
 
 func (m *marshaler) MarshalMapValue(mv reflect.Value, kv reflectutil.MapEntry, i int) (bool, error) {
 	if isCompound(kv.Value) {
-		m.inlineVal = false
 		if err := m.WriteString(":"); err != nil {
 			return false, err
 		}
@@ -233,12 +232,14 @@ func (m *marshaler) MarshalMapValue(mv reflect.Value, kv reflectutil.MapEntry, i
 		m.depth++
 		return false, nil
 	}
-	m.inlineVal = true
 	return false, m.WriteString(": ")
 }
 
 func (m *marshaler) MarshalMapValuePost(mv reflect.Value, kv reflectutil.MapEntry, i int) error {
-	if m.inlineVal {
+	// Scalar values are inline after ": ", and end the line. Don't track this
+	// in the marshaler state, since the nested values of a compound value
+	// would overwrite it.
+	if !isCompound(kv.Value) {
 		return m.WriteNewline()
 	}
 	m.depth--
@@ -250,6 +251,10 @@ func (m *marshaler) MarshalMapValuePost(mv reflect.Value, kv reflectutil.MapEntr
 	return nil
 }
 
+func (m *marshaler) MarshalStructValuePost(sv reflect.Value, kv reflectutil.MapEntry, i int) error {
+	return m.MarshalMapValuePost(sv, kv, i)
+}
+
 // MarshalNode and MarshalNodePost replay stored AST tokens verbatim, enabling
 // round-trip encoding when Encode is passed a *syntax.Document.
 func (m *marshaler) MarshalNode(node Value) error {
@@ -271,16 +276,17 @@ func (m *marshaler) MarshalNodePost(node Value) error {
 }
 
 var (
-	_ reflectutil.Marshaler             = (*marshaler)(nil)
-	_ reflectutil.PostListMarshaler     = (*marshaler)(nil)
-	_ reflectutil.PostListElemMarshaler = (*marshaler)(nil)
-	_ reflectutil.PostMapMarshaler      = (*marshaler)(nil)
-	_ reflectutil.PostMapValueMarshaler = (*marshaler)(nil)
-	_ reflectutil.Stringifier           = (*marshaler)(nil)
-	_ reflectutil.StructTagParser       = (*marshaler)(nil)
-	_ reflectutil.NaNMarshaler          = (*marshaler)(nil)
-	_ reflectutil.InfMarshaler          = (*marshaler)(nil)
-	_ reflectutil.NilMarshaler          = (*marshaler)(nil)
+	_ reflectutil.Marshaler                = (*marshaler)(nil)
+	_ reflectutil.PostListMarshaler        = (*marshaler)(nil)
+	_ reflectutil.PostListElemMarshaler    = (*marshaler)(nil)
+	_ reflectutil.PostMapMarshaler         = (*marshaler)(nil)
+	_ reflectutil.PostMapValueMarshaler    = (*marshaler)(nil)
+	_ reflectutil.PostStructValueMarshaler = (*marshaler)(nil)
+	_ reflectutil.Stringifier              = (*marshaler)(nil)
+	_ reflectutil.StructTagParser          = (*marshaler)(nil)
+	_ reflectutil.NaNMarshaler             = (*marshaler)(nil)
+	_ reflectutil.InfMarshaler             = (*marshaler)(nil)
+	_ reflectutil.NilMarshaler             = (*marshaler)(nil)
 )
 
 type EncoderOption func(*encoder)
@@ -294,17 +300,12 @@ func Marshal(v interface{}) ([]byte, error) {
 }
 
 // Save is a convenience function to save the value pointed at by v into a
-// YAML document at path. It is functionally equivalent to NewEncoder(<file at path>).Encode(v).
+// YAML document at path. It is functionally equivalent to NewEncoder(<file at path>).Encode(v),
+// except that the file is replaced atomically, with its mode preserved.
 func Save(path string, v interface{}) error {
-	f, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	if err := NewEncoder(f).Encode(v); err != nil {
-		return err
-	}
-	return f.Close()
+	return fsutil.WriteFile(path, 0, func(w io.Writer) error {
+		return NewEncoder(w).Encode(v)
+	})
 }
 
 // encodeDocument re-encodes a parsed YAML document verbatim by replaying all
@@ -224,7 +224,7 @@ func runYAMLParserSuite(t *testing.T, dir string, schema *Schema, skip []string)
 			var actualDocs []interface{}
 			for _, doc := range docs {
 				var v interface{}
-				if err := reflectutil.Unmarshal(reflect.ValueOf(&v).Elem(), doc.Root, encoding.SnakeCase, true, u); err != nil {
+				if err := reflectutil.Unmarshal(reflect.ValueOf(&v).Elem(), doc.Root, encoding.SnakeCase, "", true, u); err != nil {
 					t.Fatal(err)
 				}
 				actualDocs = append(actualDocs, v)
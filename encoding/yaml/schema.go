@@ -326,6 +326,15 @@ func (schema *Schema) resolveTag(tag string) (string, error) {
 	return tag, nil
 }
 
+// ResolveScalar returns the node of the plain (unquoted) scalar s, typed
+// according to the resolution rules of the schema: for instance, "true"
+// is a boolean and "8080" a number in the YAML 1.2 core schema. The node
+// holds s as its only token.
+func (schema *Schema) ResolveScalar(s string) (Value, error) {
+	base := Node{Tokens: []Token{{Type: TokenScalar, Raw: s, Value: s}}}
+	return schema.process(context.Background(), base, TaggedValue{Scalar: s})
+}
+
 // process resolves and processes a YAML scalar value into a typed Value.
 // base contains token annotations; val carries the tag and scalar text.
 func (schema *Schema) process(ctx context.Context, base Node, val TaggedValue) (Value, error) {
@@ -6,7 +6,15 @@
 package boa
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
 	"testing"
+
+	"snai.pe/boa/encoding/json5"
+	"snai.pe/boa/encoding/toml"
 )
 
 func TestNoFilesWithEnv(t *testing.T) {
@@ -37,3 +45,352 @@ func TestNoFilesWithEnv(t *testing.T) {
 		t.Fatalf("expected bar, got %q", config.Unprefixed)
 	}
 }
+
+func TestEnvNaming(t *testing.T) {
+	type Config struct {
+		HTTPServer struct {
+			Port int
+		} `name:"http-server"`
+		HTTP struct {
+			ServerPort int
+		}
+		Database struct {
+			URL string `env:"DB_URL, DATABASE_URL"`
+		}
+		Cache struct {
+			Size int
+		} `envPrefix:"REDIS"`
+	}
+
+	var config Config
+	err := toml.NewDecoder(strings.NewReader("")).Option(
+		AutomaticEnv("BOA"),
+		EnvSeparator("__"),
+		Environ([]string{
+			"BOA__HTTP_SERVER__PORT=1",
+			"BOA__HTTP__SERVER_PORT=2",
+			"DATABASE_URL=postgres://db",
+			"REDIS__SIZE=3",
+		}),
+	).Decode(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.HTTPServer.Port != 1 {
+		t.Errorf("expected http-server.port to be 1, got %d", config.HTTPServer.Port)
+	}
+	if config.HTTP.ServerPort != 2 {
+		t.Errorf("expected http.server-port to be 2, got %d", config.HTTP.ServerPort)
+	}
+	if config.Database.URL != "postgres://db" {
+		t.Errorf("expected the DATABASE_URL fallback, got %q", config.Database.URL)
+	}
+	if config.Cache.Size != 3 {
+		t.Errorf("expected cache.size to be 3, got %d", config.Cache.Size)
+	}
+}
+
+func TestEnvTagWithoutPrefix(t *testing.T) {
+	type Config struct {
+		Nested struct {
+			Value string `env:"VALUE"`
+		}
+	}
+
+	var config Config
+	err := toml.NewDecoder(strings.NewReader("")).Option(Environ([]string{"VALUE=foo"})).Decode(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Nested.Value != "foo" {
+		t.Fatalf("expected foo, got %q", config.Nested.Value)
+	}
+}
+
+func TestEnvCollections(t *testing.T) {
+	type Upstream struct {
+		Host string
+		Port int
+	}
+	type Config struct {
+		Upstreams []Upstream
+		Labels    map[string]string
+		Servers   map[string]*Upstream
+	}
+
+	config := Config{
+		Upstreams: []Upstream{{Host: "a", Port: 1}},
+		Servers:   map[string]*Upstream{"web": {Host: "localhost", Port: 80}},
+	}
+	err := toml.NewDecoder(strings.NewReader("")).Option(
+		AutomaticEnv("APP"),
+		EnvCollections(),
+		Environ([]string{
+			"APP_UPSTREAMS_0_PORT=2",
+			"APP_UPSTREAMS_1_HOST=b",
+			"APP_UPSTREAMS_1_PORT=3",
+			"APP_LABELS_team=infra",
+			"APP_SERVERS_web_PORT=8080",
+			"APP_SERVERS_db_HOST=db",
+		}),
+	).Decode(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Config{
+		Upstreams: []Upstream{{Host: "a", Port: 2}, {Host: "b", Port: 3}},
+		Labels:    map[string]string{"team": "infra"},
+		Servers: map[string]*Upstream{
+			"web": {Host: "localhost", Port: 8080},
+			"db":  {Host: "db"},
+		},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, config)
+	}
+
+	err = toml.NewDecoder(strings.NewReader("")).Option(
+		AutomaticEnv("APP"),
+		EnvCollections(),
+		Environ([]string{"APP_UPSTREAMS_10_HOST=c"}),
+	).Decode(&config)
+	if err == nil || !strings.Contains(err.Error(), "index out of range") {
+		t.Fatalf("expected an out of range error, got %v", err)
+	}
+}
+
+func TestEnvCollectionsEnvironFunc(t *testing.T) {
+	t.Setenv("APP_LABELS_team", "infra")
+	t.Setenv("APP_LABELS_hidden", "secret")
+
+	// The variables are listed with the same lookup function.
+	lookup := func(k string) (string, bool) {
+		if k == "APP_LABELS_hidden" {
+			return "", false
+		}
+		if k == "APP_LABELS_team" {
+			return "platform", true
+		}
+		return os.LookupEnv(k)
+	}
+
+	var config struct {
+		Labels map[string]string
+	}
+	err := toml.NewDecoder(strings.NewReader("")).Option(
+		AutomaticEnv("APP"),
+		EnvCollections(),
+		EnvironFunc(lookup),
+	).Decode(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]string{"team": "platform"}; !reflect.DeepEqual(config.Labels, expected) {
+		t.Fatalf("expected %v, got %v", expected, config.Labels)
+	}
+}
+
+func TestStructuredEnv(t *testing.T) {
+	type Peer struct {
+		Host string
+		Port int
+	}
+	type Config struct {
+		Peers  []Peer
+		Server Peer
+		Labels map[string]string
+	}
+
+	for _, ext := range []string{".json5", ".yaml"} {
+		t.Run(ext, func(t *testing.T) {
+			config := Config{
+				Labels: map[string]string{"team": "infra"},
+			}
+			err := toml.NewDecoder(strings.NewReader("")).Option(
+				AutomaticEnv("APP"),
+				StructuredEnv(ext),
+				Environ([]string{
+					`APP_PEERS=[{host: "a", port: 1}, {host: "b", port: 2}]`,
+					`APP_SERVER={host: "localhost", port: 80}`,
+					`APP_SERVER_PORT=8080`,
+					`APP_LABELS={"env": "prod"}`,
+				}),
+			).Decode(&config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := Config{
+				Peers:  []Peer{{"a", 1}, {"b", 2}},
+				Server: Peer{"localhost", 8080},
+				Labels: map[string]string{"team": "infra", "env": "prod"},
+			}
+			if !reflect.DeepEqual(config, expected) {
+				t.Fatalf("expected %+v, got %+v", expected, config)
+			}
+		})
+	}
+
+	var config Config
+	err := toml.NewDecoder(strings.NewReader("")).Option(
+		AutomaticEnv("APP"),
+		StructuredEnv(".json5"),
+		Environ([]string{`APP_PEERS=[{host: }]`}),
+	).Decode(&config)
+	if err == nil || !strings.HasPrefix(err.Error(), "APP_PEERS: ") {
+		t.Fatalf("expected a parse error for APP_PEERS, got %v", err)
+	}
+}
+
+func TestStructuredEnvLoader(t *testing.T) {
+	var config struct {
+		Labels map[string]string
+	}
+	env := Environ([]string{`APP_LABELS={team: "infra"}`})
+
+	// The languages of the loader parse the variables, rather than the
+	// package-level ones.
+	err := NewLoader().
+		Decoder(".conf", json5.NewDecoder).
+		Paths().
+		Env("APP").
+		Option(StructuredEnv(".conf"), env).
+		Load(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]string{"team": "infra"}; !reflect.DeepEqual(config.Labels, expected) {
+		t.Fatalf("expected %v, got %v", expected, config.Labels)
+	}
+
+	err = NewLoader().
+		Decoder(".json5", nil).
+		Paths().
+		Env("APP").
+		Option(StructuredEnv(".json5"), env).
+		Load(&config)
+	if err == nil || !strings.Contains(err.Error(), `".json5"`) {
+		t.Fatalf("expected an unknown extension error, got %v", err)
+	}
+}
+
+func TestListSeparator(t *testing.T) {
+	type Config struct {
+		Paths   []string
+		Ports   []int    `sep:","`
+		Origins []string `sep:", "`
+		Nested  struct {
+			Tags []string
+		} `sep:"|"`
+	}
+
+	in := `
+ports = "80,443"
+origins = ["https://a.com"]
+[nested]
+tags = "a|b"
+`
+	var config Config
+	err := toml.NewDecoder(strings.NewReader(in)).Option(
+		AutomaticEnv("APP"),
+		Environ([]string{"APP_ORIGINS=https://a.com, https://b.com"}),
+	).Decode(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expected Config
+	expected.Ports = []int{80, 443}
+	expected.Origins = []string{"https://a.com", "https://b.com"}
+	expected.Nested.Tags = []string{"a", "b"}
+	if !reflect.DeepEqual(config, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, config)
+	}
+
+	err = toml.NewDecoder(strings.NewReader(`paths = "a;b"`)).Option(
+		AutomaticEnv("APP"),
+		ListSeparator(";"),
+		Environ([]string{"APP_PORTS=8080,8443"}),
+	).Decode(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(config.Paths, []string{"a", "b"}) {
+		t.Fatalf("expected [a b], got %q", config.Paths)
+	}
+	if !reflect.DeepEqual(config.Ports, []int{8080, 8443}) {
+		t.Fatalf("expected [8080 8443], got %v", config.Ports)
+	}
+
+	err = toml.NewDecoder(strings.NewReader(`ports = "80,http"`)).Decode(&config)
+	if err == nil || !strings.Contains(err.Error(), "element 1") {
+		t.Fatalf("expected a conversion error on element 1, got %v", err)
+	}
+
+	// Without a separator, strings are not lists.
+	err = toml.NewDecoder(strings.NewReader(`paths = "/bin:/usr/bin"`)).Decode(&config)
+	if err == nil || !strings.Contains(err.Error(), "expected list") {
+		t.Fatalf("expected a type error, got %v", err)
+	}
+}
+
+func TestState(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("XDG state paths require a unix system")
+	}
+	home := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", home)
+
+	type State struct {
+		Recent []string
+		Width  int
+	}
+
+	var state State
+	if err := LoadState("myapp/state", &state); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(state, State{}) {
+		t.Fatalf("expected empty state, got %+v", state)
+	}
+
+	// Legacy configuration files are not state files.
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+	if err := os.WriteFile(filepath.Join(home, ".staterc"), []byte("width = 1024\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	SetLegacy("myapp/state", Legacy{
+		Paths:   []LegacyPath{{Path: "~/.staterc", Ext: ".toml"}},
+		Warn:    func(string) {},
+		Migrate: true,
+	})
+	defer delete(legacyConfigs, "myapp/state")
+	if err := LoadState("myapp/state", &state); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(state, State{}) {
+		t.Fatalf("expected the legacy file to be ignored, got %+v", state)
+	}
+	if _, err := os.Stat(filepath.Join(home, "config", "myapp")); !os.IsNotExist(err) {
+		t.Fatalf("expected no migration into the configuration home, got %v", err)
+	}
+
+	state = State{Recent: []string{"a.txt", "b.txt"}, Width: 800}
+	if err := SaveState("myapp/state.json5", &state); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(home, "myapp", "state.json5")); err != nil {
+		t.Fatal(err)
+	}
+
+	var loaded State
+	if err := LoadState("myapp/state", &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(state, loaded) {
+		t.Fatalf("expected %+v, got %+v", state, loaded)
+	}
+}
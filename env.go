@@ -0,0 +1,90 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"fmt"
+	"reflect"
+
+	"snai.pe/boa/encoding"
+	"snai.pe/boa/internal/reflectutil"
+)
+
+// EnvVar describes an environment variable that a configuration is populated
+// from.
+type EnvVar struct {
+	// Name is the name of the variable.
+	Name string
+
+	// Path is the path of the value that the variable sets, made of struct
+	// field names and map keys.
+	Path []string
+
+	// Type is the type of the value that the variable sets.
+	Type reflect.Type
+
+	// Help contains the lines of the `help` tag of the field, if any.
+	Help []string
+
+	// Explicit is true if the variable is named by an `env` tag, and false
+	// if it is derived from the field path, as with AutomaticEnv.
+	Explicit bool
+
+	// Value is the current value that the variable sets, or the invalid
+	// Value if it is unset because of a nil pointer.
+	Value reflect.Value
+
+	// ListSeparator separates the elements of the value if it is a list
+	// (see ListSeparator).
+	ListSeparator string
+}
+
+// EnvVars returns every environment variable that populates the configuration
+// value pointed at by v when loaded with AutomaticEnv(prefix), in the order in
+// which they are read. If prefix is empty, only the variables of fields with
+// an `env` or `envPrefix` tag are returned.
+//
+// When a value can be set by several variables, such as with an `env` tag
+// listing fallbacks, each of them is returned, by order of precedence. Map
+// entries are only returned if they exist in v.
+//
+// The variable names are formed according to the EnvNamingConvention and
+// EnvSeparator options that were set with SetOptions, or that are passed in
+// opts, and the separators of lists according to the ListSeparator option.
+func EnvVars(v interface{}, prefix string, opts ...interface{}) []EnvVar {
+	var (
+		common  encoding.CommonOptions
+		decoder encoding.DecoderOptions
+	)
+	for _, opt := range append(append([]interface{}(nil), defaultDecoderOptions...), opts...) {
+		switch setopt := opt.(type) {
+		case CommonOption:
+			setopt(&common)
+		case DecoderOption:
+			setopt(&decoder)
+		default:
+			panic(fmt.Sprintf("%T is not a common option, nor a decoder option.", opt))
+		}
+	}
+	env := reflectutil.Env{
+		Naming:    common.EnvNamingConvention,
+		Separator: common.EnvSeparator,
+
+		ListSeparator: decoder.ListSeparator,
+	}
+
+	var names []string
+	if prefix != "" {
+		names = []string{prefix}
+	}
+
+	vars := reflectutil.EnvVars(reflect.ValueOf(v), prefix != "", names, env)
+	out := make([]EnvVar, len(vars))
+	for i, v := range vars {
+		out[i] = EnvVar(v)
+	}
+	return out
+}
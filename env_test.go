@@ -0,0 +1,97 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEnvVars(t *testing.T) {
+	type Config struct {
+		Name     string `help:"Name of the service"`
+		Database struct {
+			URL string `env:"DB_URL,DATABASE_URL"`
+		}
+		Cache struct {
+			Size int
+		} `envPrefix:"REDIS"`
+		Tags   []string
+		Labels map[string]string
+		Proxy  *struct {
+			Host string
+		}
+	}
+
+	config := Config{Labels: map[string]string{"team": "infra"}}
+
+	type result struct {
+		Name     string
+		Path     string
+		Type     reflect.Type
+		Explicit bool
+	}
+	results := func(vars []EnvVar) []result {
+		out := make([]result, len(vars))
+		for i, v := range vars {
+			path := ""
+			for j, p := range v.Path {
+				if j > 0 {
+					path += "."
+				}
+				path += p
+			}
+			out[i] = result{v.Name, path, v.Type, v.Explicit}
+		}
+		return out
+	}
+
+	var (
+		str  = reflect.TypeOf("")
+		num  = reflect.TypeOf(0)
+		strs = reflect.TypeOf([]string(nil))
+	)
+
+	expected := []result{
+		{"APP_NAME", "Name", str, false},
+		{"DB_URL", "Database.URL", str, true},
+		{"DATABASE_URL", "Database.URL", str, true},
+		{"REDIS_SIZE", "Cache.Size", num, false},
+		{"APP_TAGS", "Tags", strs, false},
+		{"APP_LABELS_TEAM", "Labels.team", str, false},
+		{"APP_PROXY_HOST", "Proxy.Host", str, false},
+	}
+	vars := EnvVars(&config, "APP")
+	if actual := results(vars); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	if len(vars) > 0 && !reflect.DeepEqual(vars[0].Help, []string{"Name of the service"}) {
+		t.Errorf("expected help of Name, got %q", vars[0].Help)
+	}
+
+	// Without a prefix, only the tagged fields are populated.
+	expected = []result{
+		{"DB_URL", "Database.URL", str, true},
+		{"DATABASE_URL", "Database.URL", str, true},
+		{"REDIS_SIZE", "Cache.Size", num, false},
+	}
+	if actual := results(EnvVars(&config, "")); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	expected = []result{
+		{"APP__NAME", "Name", str, false},
+		{"DB_URL", "Database.URL", str, true},
+		{"DATABASE_URL", "Database.URL", str, true},
+		{"REDIS__SIZE", "Cache.Size", num, false},
+		{"APP__TAGS", "Tags", strs, false},
+		{"APP__LABELS__TEAM", "Labels.team", str, false},
+		{"APP__PROXY__HOST", "Proxy.Host", str, false},
+	}
+	if actual := results(EnvVars(&config, "APP", EnvSeparator("__"))); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
@@ -0,0 +1,291 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	stdenc "encoding"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"snai.pe/boa/encoding"
+	"snai.pe/boa/internal/reflectutil"
+)
+
+// EnvFormat is the syntax of the variable assignments that an environment
+// encoder writes.
+type EnvFormat int
+
+const (
+	// EnvFormatDotenv writes KEY=value lines, where values are double-quoted
+	// when needed, as read by snai.pe/boa/encoding/dotenv.
+	EnvFormatDotenv EnvFormat = iota
+
+	// EnvFormatShell writes `export KEY=value` lines, where values are
+	// single-quoted when needed, to be sourced by POSIX shells.
+	EnvFormatShell
+
+	// EnvFormatDocker writes KEY=value lines with verbatim values, as read
+	// by `docker run --env-file`. Values cannot contain line breaks.
+	EnvFormatDocker
+)
+
+type envEncoder struct {
+	out    io.Writer
+	prefix string
+	format EnvFormat
+
+	encoding.CommonOptions
+	encoding.EncoderOptions
+	encoding.DecoderOptions
+}
+
+// NewEnvEncoder returns an encoder that writes the encoded configuration
+// value into out as environment variable assignments, in the specified
+// format.
+//
+// It is the inverse of AutomaticEnv(prefix): every value that the
+// configuration is populated from when loaded with AutomaticEnv(prefix) is
+// assigned to the variable of highest precedence among the ones that can set
+// it. If prefix is empty, only the values of fields with an `env` or
+// `envPrefix` tag are written. Nil values are omitted.
+//
+// Lists of values are joined with their separator (see ListSeparator). Lists
+// of other lists, maps, or structs, and lists with elements that contain the
+// separator are written element by element, with their index as key; like
+// map entries that do not exist in the configuration, they can only be read
+// back with EnvCollections (which then keys the map entries by their
+// variable name).
+//
+// The variable names are formed according to the EnvNamingConvention and
+// EnvSeparator options that were set with SetOptions, or that are passed to
+// the Option method.
+func NewEnvEncoder(out io.Writer, prefix string, format EnvFormat) encoding.Encoder {
+	enc := &envEncoder{out: out, prefix: prefix, format: format}
+	for _, opt := range defaultDecoderOptions {
+		switch setopt := opt.(type) {
+		case CommonOption:
+			setopt(&enc.CommonOptions)
+		case DecoderOption:
+			setopt(&enc.DecoderOptions)
+		}
+	}
+	return enc
+}
+
+func (enc *envEncoder) Option(opts ...interface{}) encoding.Encoder {
+	for _, opt := range opts {
+		switch setopt := opt.(type) {
+		case CommonOption:
+			setopt(&enc.CommonOptions)
+		case EncoderOption:
+			setopt(&enc.EncoderOptions)
+		case DecoderOption:
+			setopt(&enc.DecoderOptions)
+		default:
+			panic(fmt.Sprintf("%T is not a common option, an encoder option, nor a decoder option.", opt))
+		}
+	}
+	return enc
+}
+
+func (enc *envEncoder) env() reflectutil.Env {
+	return reflectutil.Env{
+		Naming:    enc.EnvNamingConvention,
+		Separator: enc.EnvSeparator,
+
+		ListSeparator: enc.ListSeparator,
+	}
+}
+
+func (enc *envEncoder) Encode(v interface{}) error {
+	var names []string
+	if enc.prefix != "" {
+		names = []string{enc.prefix}
+	}
+	vars := reflectutil.EnvVars(reflect.ValueOf(v), enc.prefix != "", names, enc.env())
+
+	var out strings.Builder
+	if err := enc.encode(&out, vars); err != nil {
+		return err
+	}
+	_, err := io.WriteString(enc.out, out.String())
+	return err
+}
+
+func (enc *envEncoder) encode(out *strings.Builder, vars []reflectutil.EnvVar) error {
+	for i, v := range vars {
+		// Only the first of the variables that set a value takes effect.
+		if i > 0 && reflect.DeepEqual(v.Path, vars[i-1].Path) {
+			continue
+		}
+		if err := enc.encodeValue(out, v.Name, v.ListSeparator, v.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (enc *envEncoder) encodeValue(out *strings.Builder, name, sep string, val reflect.Value) error {
+	for val.IsValid() && (val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface) {
+		val = val.Elem()
+	}
+	if !val.IsValid() {
+		return nil
+	}
+
+	if !isText(val) && (val.Kind() == reflect.Slice || val.Kind() == reflect.Array) {
+		if val.Len() == 0 {
+			return nil
+		}
+		elems := make([]string, val.Len())
+		joined := true
+		for i := range elems {
+			elem, ok, err := envText(val.Index(i))
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			if !ok || strings.Contains(elem, sep) {
+				joined = false
+				break
+			}
+			elems[i] = elem
+		}
+		if joined {
+			return enc.assign(out, name, strings.Join(elems, sep))
+		}
+
+		esep := enc.EnvSeparator
+		if esep == "" {
+			esep = "_"
+		}
+		env := enc.env()
+		env.ListSeparator = sep
+		for i := 0; i < val.Len(); i++ {
+			ename := name + esep + strconv.Itoa(i)
+			vars := reflectutil.EnvVars(val.Index(i), true, []string{ename}, env)
+			if err := enc.encode(out, vars); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	text, ok, err := envText(val)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if !ok {
+		return fmt.Errorf("%s: cannot write %v as an environment variable", name, val.Type())
+	}
+	return enc.assign(out, name, text)
+}
+
+var textMarshalerType = reflect.TypeOf((*stdenc.TextMarshaler)(nil)).Elem()
+
+// isText returns whether values of the type of val are written as text rather
+// than as lists.
+func isText(val reflect.Value) bool {
+	typ := val.Type()
+	return reflect.PointerTo(typ).Implements(textMarshalerType) || typ == reflect.TypeOf([]byte(nil))
+}
+
+// envText returns the textual representation of val that UnmarshalText
+// parses back, or false if val has none.
+func envText(val reflect.Value) (string, bool, error) {
+	for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return "", true, nil
+		}
+		val = val.Elem()
+	}
+
+	iface := val.Interface()
+	if val.CanAddr() {
+		iface = val.Addr().Interface()
+	}
+	switch v := iface.(type) {
+	case stdenc.TextMarshaler:
+		txt, err := v.MarshalText()
+		return string(txt), err == nil, err
+	case []byte:
+		return string(v), true, nil
+	case *[]byte:
+		return string(*v), true, nil
+	}
+
+	switch val.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(val.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(val.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(val.Uint(), 10), true, nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(val.Float(), 'g', -1, val.Type().Bits()), true, nil
+	case reflect.Complex64, reflect.Complex128:
+		return strconv.FormatComplex(val.Complex(), 'g', -1, val.Type().Bits()), true, nil
+	case reflect.String:
+		return val.String(), true, nil
+	}
+	return "", false, nil
+}
+
+func (enc *envEncoder) assign(out *strings.Builder, name, value string) error {
+	switch enc.format {
+	case EnvFormatShell:
+		out.WriteString("export ")
+		out.WriteString(name)
+		out.WriteByte('=')
+		out.WriteString(shellQuote(value))
+	case EnvFormatDocker:
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("%s: docker env files cannot hold values with line breaks", name)
+		}
+		out.WriteString(name)
+		out.WriteByte('=')
+		out.WriteString(value)
+	default:
+		out.WriteString(name)
+		out.WriteByte('=')
+		out.WriteString(dotenvQuote(value))
+	}
+	lb := enc.LineBreak
+	if lb == "" {
+		lb = "\n"
+	}
+	out.WriteString(lb)
+	return nil
+}
+
+// isBare returns whether s can be written as-is, without quotes, in both
+// dotenv files and shell scripts.
+func isBare(s string) bool {
+	return s != "" && strings.IndexFunc(s, func(r rune) bool {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return false
+		}
+		return !strings.ContainsRune("_-./:,@+%=", r)
+	}) == -1
+}
+
+func shellQuote(s string) string {
+	if isBare(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func dotenvQuote(s string) string {
+	if isBare(s) {
+		return s
+	}
+	r := strings.NewReplacer(`"`, `\"`, `\`, `\\`, `$`, `\$`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"snai.pe/boa/encoding/dotenv"
+)
+
+func TestEnvEncoder(t *testing.T) {
+	type Upstream struct {
+		Host string
+		Port int
+	}
+	type Config struct {
+		Name     string
+		Greeting string
+		Ratio    float64
+		Debug    bool
+		Database struct {
+			URL string `env:"DB_URL,DATABASE_URL"`
+		}
+		Ports     []int
+		Paths     []string
+		Origins   []string `sep:","`
+		Upstreams []Upstream
+		Labels    map[string]string
+		Addr      net.IP
+		Proxy     *Upstream
+		Empty     []string
+	}
+
+	config := Config{
+		Name:      "svc",
+		Greeting:  "it's \"$HOME\"\n",
+		Ratio:     0.25,
+		Debug:     true,
+		Ports:     []int{80, 443},
+		Paths:     []string{"/usr/bin", "a:b"},
+		Origins:   []string{"https://a.com", "https://b.com"},
+		Upstreams: []Upstream{{"a", 1}, {"b", 2}},
+		Labels:    map[string]string{"team": "infra"},
+		Addr:      net.IPv4(127, 0, 0, 1),
+	}
+	config.Database.URL = "postgres://db/app"
+
+	var out strings.Builder
+	if err := NewEnvEncoder(&out, "APP", EnvFormatDotenv).Encode(&config); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `APP_NAME=svc
+APP_GREETING="it's \"\$HOME\"\n"
+APP_RATIO=0.25
+APP_DEBUG=true
+DB_URL=postgres://db/app
+APP_PORTS=80:443
+APP_PATHS_0=/usr/bin
+APP_PATHS_1=a:b
+APP_ORIGINS=https://a.com,https://b.com
+APP_UPSTREAMS_0_HOST=a
+APP_UPSTREAMS_0_PORT=1
+APP_UPSTREAMS_1_HOST=b
+APP_UPSTREAMS_1_PORT=2
+APP_LABELS_TEAM=infra
+APP_ADDR=127.0.0.1
+`
+	if out.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	vars, err := dotenv.Read(strings.NewReader(out.String()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	environ := make([]string, 0, len(vars))
+	for k, v := range vars {
+		environ = append(environ, fmt.Sprintf("%s=%s", k, v))
+	}
+
+	// Map entries are matched by key when they exist.
+	loaded := Config{Labels: map[string]string{"team": ""}}
+	err = NewDecoder(Open("app", fstest.MapFS{})).
+		Option(AutomaticEnv("APP"), Environ(environ), EnvCollections()).
+		Decode(&loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, config) {
+		t.Fatalf("expected %+v, got %+v", config, loaded)
+	}
+
+	out.Reset()
+	short := struct{ Greeting, Name string }{"it's", "svc"}
+	if err := NewEnvEncoder(&out, "APP", EnvFormatShell).Encode(&short); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "export APP_GREETING='it'\\''s'\nexport APP_NAME=svc\n"; out.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	out.Reset()
+	if err := NewEnvEncoder(&out, "APP", EnvFormatDocker).Encode(&short); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "APP_GREETING=it's\nAPP_NAME=svc\n"; out.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+	if err := NewEnvEncoder(&out, "APP", EnvFormatDocker).Encode(&config); err == nil {
+		t.Fatal("expected error when writing a line break in a docker env file")
+	}
+}
@@ -6,13 +6,16 @@
 package boa
 
 import (
+	"context"
 	"embed"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -26,6 +29,30 @@ type FileSet struct {
 	opened    fs.File
 	closed    bool
 	used      []string
+
+	// dropins holds the paths of the drop-in files of the current search
+	// path that remain to be opened, once its main file has been visited.
+	dropins  []string
+	inDropin bool
+
+	// found records whether a file matched the current name, in which case
+	// its legacy locations are not consulted.
+	found  bool
+	legacy *legacyFallback
+
+	// ctx scopes the opening of files in the paths that support it, such as
+	// HTTPFS.
+	ctx context.Context
+
+	// namePaths overrides the search paths of specific names, such as the
+	// one that Loader.ConfigFile overrides.
+	namePaths map[string][]fs.FS
+}
+
+// contextFS is implemented by the file systems whose files are opened with
+// a context, such as HTTPFS.
+type contextFS interface {
+	OpenContext(ctx context.Context, name string) (fs.File, error)
 }
 
 // Open opens a set of configuration files by name.
@@ -42,12 +69,15 @@ type FileSet struct {
 // Names with an extension will restrict the search to the files matching the
 // specified extension. Conversely, names without an extension will match all
 // files whose stem is the last path component of the filename.
+//
+// In every search path, the files of the <stem>.d drop-in directory, such as
+// myapp.d/10-network.toml, are opened after the main file in lexical order
+// (see Next).
+//
+// If no path is provided, and no file matches the name, the legacy locations
+// set by SetLegacy are consulted.
 func Open(name string, paths ...fs.FS) *FileSet {
-	checkNames(name)
-	if paths == nil {
-		paths = ConfigPaths()
-	}
-	return &FileSet{fs: paths, names: []string{name}}
+	return OpenMultiple([]string{name}, paths...)
 }
 
 // OpenMultiple opens a set of configuration files with one or more names.
@@ -58,10 +88,12 @@ func Open(name string, paths ...fs.FS) *FileSet {
 // The iteration order is described in detail in `Next()`.
 func OpenMultiple(names []string, paths ...fs.FS) *FileSet {
 	checkNames(names...)
+	var legacy *legacyFallback
 	if paths == nil {
 		paths = ConfigPaths()
+		legacy = &legacyFallback{configs: legacyConfigs, decoders: Decoders, encoders: Encoders, home: defaultLoader().homeWritableFS}
 	}
-	return &FileSet{fs: paths, names: names}
+	return &FileSet{fs: paths, names: names, legacy: legacy}
 }
 
 func checkNames(names ...string) {
@@ -110,6 +142,14 @@ func fsPath(f fs.FS) string {
 		return "(embedded)"
 	case singleFileFS:
 		return fmt.Sprintf("%s (as %s)", f.Path, f.Name)
+	case keyPerFileFS:
+		return fmt.Sprintf("%s (as %s)", fsPath(f.Dir), f.Name)
+	case projectFS:
+		return f.Dir
+	case dirFS:
+		return f.Dir
+	case stdinFS:
+		return "(stdin)"
 	default:
 		return fmt.Sprint(f)
 	}
@@ -135,10 +175,20 @@ func fsPath(f fs.FS) string {
 // This ordering of the names slice ensures that later entries take precedence over
 // earlier ones, regardless of which directory they're in.
 //
+// If no file matches a name in any of the paths, and the FileSet consults
+// legacy locations (see SetLegacy), the first legacy file of that name is
+// opened instead, after the paths.
+//
 // The files are matched in the order of the specified exts slice rather than
 // directory (or lexical) order. For instance, if the extension slice
 // is ".json5", ".json" on a path containing <name>.json5 and <name>.json will
 // open <name>.json5 if it exists, or then <name>.json.
+//
+// After the main file of a path, or in its absence, the files of the
+// <stem>.d drop-in directory of that path whose extension is in exts are
+// opened, in lexical order of their names. This lets packagers and
+// configuration management tools add configuration fragments, such as
+// /etc/myapp.d/50-logging.toml, without editing the main file.
 func (cfg *FileSet) Next(exts ...string) error {
 	if cfg.opened != nil {
 		// Close errors are ignored. FileSet only reads files, so close errors
@@ -147,31 +197,59 @@ func (cfg *FileSet) Next(exts ...string) error {
 	}
 
 	for ; cfg.nameIndex < len(cfg.names); cfg.nameIndex++ {
-		for ; cfg.fsIndex < len(cfg.fs); cfg.fsIndex++ {
-			for _, ext := range exts {
-				name := cfg.names[cfg.nameIndex]
-				realext := filepath.Ext(name)
-				if realext != "" && realext != ext {
-					continue
+		paths := cfg.paths()
+		for ; cfg.fsIndex < len(paths); cfg.fsIndex++ {
+			fsys := paths[cfg.fsIndex]
+			if fsys == nil {
+				// Skip the paths of NewSingleFileFS and NewCredentialsFS
+				// that have nothing to open.
+				continue
+			}
+			name := cfg.names[cfg.nameIndex]
+			realext := filepath.Ext(name)
+			stem := name[:len(name)-len(realext)]
+
+			if !cfg.inDropin {
+				dropins, err := cfg.listDropins(fsys, stem+".d", realext, exts)
+				if err != nil {
+					return err
 				}
-				stem := name[:len(name)-len(realext)]
-				f, err := cfg.fs[cfg.fsIndex].Open(stem + ext)
-				switch {
-				case errors.Is(err, fs.ErrNotExist):
-					continue
-				case err != nil:
-					pathError := &fs.PathError{}
-					if ok := errors.As(err, &pathError); ok {
-						pathError.Path = filepath.Join(fsPath(cfg.fs[cfg.fsIndex]), stem, ext)
+				cfg.dropins = dropins
+				cfg.inDropin = true
+
+				for _, ext := range exts {
+					if realext != "" && realext != ext {
+						continue
+					}
+					if ok, err := cfg.open(fsys, stem+ext); ok || err != nil {
+						return err
 					}
+				}
+			}
+
+			for len(cfg.dropins) > 0 {
+				dropin := cfg.dropins[0]
+				cfg.dropins = cfg.dropins[1:]
+				if ok, err := cfg.open(fsys, dropin); ok || err != nil {
 					return err
 				}
-				cfg.used = append(cfg.used, fmt.Sprintf("%v/%v%v", fsPath(cfg.fs[cfg.fsIndex]), stem, ext))
+			}
+			cfg.inDropin = false
+		}
+		if !cfg.found && cfg.legacy != nil {
+			cfg.found = true
+			f, path, err := cfg.legacy.open(cfg.names[cfg.nameIndex], exts)
+			if err != nil {
+				return err
+			}
+			if f != nil {
+				cfg.used = append(cfg.used, path)
 				cfg.opened = f
-				cfg.Skip()
 				return nil
 			}
 		}
+		cfg.found = false
+		cfg.fsIndex = 0
 	}
 	if cfg.opened == nil {
 		// Nothing matched, but feed at the very least an empty file to the decoder.
@@ -185,6 +263,102 @@ func (cfg *FileSet) Next(exts ...string) error {
 	return os.ErrNotExist
 }
 
+// paths returns the search paths of the current name.
+func (cfg *FileSet) paths() []fs.FS {
+	if cfg.nameIndex < len(cfg.names) {
+		if paths, ok := cfg.namePaths[cfg.names[cfg.nameIndex]]; ok {
+			return paths
+		}
+	}
+	return cfg.fs
+}
+
+// open opens the file at the specified path in fsys, and returns whether it
+// exists.
+func (cfg *FileSet) open(fsys fs.FS, name string) (bool, error) {
+	f, err := cfg.openFile(fsys, name)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return false, nil
+	case err != nil:
+		pathError := &fs.PathError{}
+		if ok := errors.As(err, &pathError); ok {
+			pathError.Path = joinPath(fsys, name)
+		}
+		cfg.found = true
+		return false, err
+	}
+	if project, ok := fsys.(projectFS); ok {
+		name = project.realName(name)
+	}
+	cfg.used = append(cfg.used, fmt.Sprintf("%v/%v", fsPath(fsys), name))
+	cfg.opened = f
+	cfg.found = true
+	return true, nil
+}
+
+// openFile opens the named file of fsys, with the context of the FileSet if
+// fsys supports it.
+func (cfg *FileSet) openFile(fsys fs.FS, name string) (fs.File, error) {
+	if cfs, ok := fsys.(contextFS); ok && cfg.ctx != nil {
+		return cfs.OpenContext(cfg.ctx, name)
+	}
+	return fsys.Open(name)
+}
+
+// joinPath returns the path of the named file of fsys, for error messages.
+func joinPath(fsys fs.FS, name string) string {
+	if f, ok := fsys.(*HTTPFS); ok {
+		return f.String() + "/" + name
+	}
+	return filepath.Join(fsPath(fsys), name)
+}
+
+// listDropins returns the paths of the files in the dir drop-in directory
+// of fsys whose extension is ext, or one of exts if ext is empty, in
+// lexical order.
+func (cfg *FileSet) listDropins(fsys fs.FS, dir, ext string, exts []string) ([]string, error) {
+	if _, ok := fsys.(*HTTPFS); ok {
+		// HTTP servers do not list directories.
+		return nil, nil
+	}
+	info, err := fs.Stat(fsys, dir)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && !info.IsDir()) {
+		return nil, nil
+	}
+	var entries []fs.DirEntry
+	if err == nil {
+		entries, err = fs.ReadDir(fsys, dir)
+	}
+	if err != nil {
+		pathError := &fs.PathError{}
+		if ok := errors.As(err, &pathError); ok {
+			pathError.Path = joinPath(fsys, dir)
+		}
+		return nil, err
+	}
+
+	var dropins []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		fext := filepath.Ext(name)
+		if ext != "" && fext != ext {
+			continue
+		}
+		for _, e := range exts {
+			if fext == e {
+				dropins = append(dropins, path.Join(dir, name))
+				break
+			}
+		}
+	}
+	sort.Strings(dropins)
+	return dropins, nil
+}
+
 // Used returns a slice containing the file names of all files that were opened by
 // calls to Next().
 func (cfg *FileSet) Used() []string {
@@ -226,8 +400,14 @@ func (cfg *FileSet) File() fs.File {
 // application would rather warn but continue parsing configurations in the
 // rest of the paths.
 func (cfg *FileSet) Skip() {
+	cfg.dropins = nil
+	cfg.inDropin = false
 	cfg.fsIndex++
-	if cfg.fsIndex == len(cfg.fs) {
+	// Past the last path, the legacy locations of the name remain to be
+	// consulted if nothing was found.
+	paths := cfg.paths()
+	if cfg.fsIndex > len(paths) || (cfg.fsIndex == len(paths) && (cfg.found || cfg.legacy == nil)) {
+		cfg.found = false
 		cfg.fsIndex = 0
 		cfg.nameIndex++
 	}
@@ -12,7 +12,9 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 func pathsForDirs(t *testing.T, dirs ...string) (paths []fs.FS) {
@@ -87,6 +89,23 @@ func TestFileset(t *testing.T) {
 			},
 			expectUsed: []string{"path1/first.json", "path1/second.toml"},
 		},
+		{
+			name: "dropins",
+			open: func(t *testing.T, c *testCase) *FileSet {
+				return OpenMultiple([]string{"first", "second"}, pathsForDirs(t, "path1", "path3")...)
+			},
+			expectUsed: []string{
+				"path1/first.json", "path3/first.toml", "first.d/10-a.toml", "first.d/20-b.json",
+				"path1/second.toml", "second.d/10-a.json",
+			},
+		},
+		{
+			name: "dropins-with-extension",
+			open: func(t *testing.T, c *testCase) *FileSet {
+				return Open("first.toml", pathsForDirs(t, "path3")...)
+			},
+			expectUsed: []string{"path3/first.toml", "first.d/10-a.toml"},
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			exts := []string{".toml", ".json", ".json5"}
@@ -111,6 +130,41 @@ func TestFileset(t *testing.T) {
 	}
 }
 
+func TestFilesetDropinsPrecedence(t *testing.T) {
+	system := fstest.MapFS{
+		"app.toml":           {Data: []byte("name = \"system\"\nport = 80\nhost = \"localhost\"\n")},
+		"app.d/20-port.yaml": {Data: []byte("port: 8080\n")},
+		"app.d/10-port.toml": {Data: []byte("port = 443\nname = \"dropin\"\n")},
+	}
+	user := fstest.MapFS{
+		"app.d/10-host.json5": {Data: []byte("{host: 'example.com'}\n")},
+	}
+
+	var config struct {
+		Name string
+		Host string
+		Port int
+	}
+	f := Open("app", system, user)
+	defer f.Close()
+	if err := NewLoader().NewDecoder(f).Decode(&config); err != nil {
+		t.Fatal(err)
+	}
+	if config.Name != "dropin" || config.Port != 8080 || config.Host != "example.com" {
+		t.Fatalf("unexpected config %+v", config)
+	}
+	used := f.Used()
+	expected := []string{"/app.toml", "/app.d/10-port.toml", "/app.d/20-port.yaml", "/app.d/10-host.json5"}
+	if len(used) != len(expected) {
+		t.Fatalf("expected %d used files, got %q", len(expected), used)
+	}
+	for i := range used {
+		if !strings.HasSuffix(used[i], expected[i]) {
+			t.Errorf("used file %d: expected %q, got %q", i, expected[i], used[i])
+		}
+	}
+}
+
 func ExampleSetConfigHomeFS() {
 
 	var config struct {
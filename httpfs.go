@@ -0,0 +1,238 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"snai.pe/boa/internal/fsutil"
+)
+
+// HTTPFS is an fs.FS whose files are fetched from a base URL, such as a
+// configuration server, so that it can be used as a search path. For
+// instance, opening myapp.toml in the HTTPFS of https://example.com/config
+// fetches https://example.com/config/myapp.toml.
+//
+// Fetched files are cached in a local directory, if any. Cached files are
+// revalidated with the ETag and Last-Modified headers of the previous
+// response. When the server is unreachable or fails, the cache is used as a
+// snapshot of the server: cached files are used as-is, and the files that are
+// not cached do not exist. Without a cache, opening files fails instead.
+//
+// Drop-in directories are not loaded from an HTTPFS, since HTTP servers do not
+// list directories. When a FileSet is decoded, requests are scoped to the
+// context of the encoding.WithContext decoder option, which can set their
+// timeout:
+//
+//	remote, err := boa.NewHTTPFS("https://config.example.com/", filepath.Join(cacheHome, "myapp"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	err = boa.NewLoader().
+//		Paths(append(boa.ConfigPaths(), remote)...).
+//		Files("myapp").
+//		Option(encoding.WithContext(ctx)).
+//		Load(&config)
+type HTTPFS struct {
+	// Client is the client that sends the requests. If nil, a client with a
+	// 30-second timeout is used.
+	Client *http.Client
+
+	base     *url.URL
+	cacheDir string
+}
+
+// NewHTTPFS returns a new HTTPFS for the files under baseURL. If cacheDir is
+// not empty, fetched files are cached in that directory, which is created if
+// needed.
+func NewHTTPFS(baseURL, cacheDir string) (*HTTPFS, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, fmt.Errorf("%s: not an HTTP or HTTPS URL", baseURL)
+	}
+	// Make relative references resolve under the last path component.
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+		base.RawPath = ""
+	}
+	return &HTTPFS{base: base, cacheDir: cacheDir}, nil
+}
+
+var defaultHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// httpCacheMeta holds the validators of a cached file.
+type httpCacheMeta struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// Open opens the named file, as OpenContext does with a background context.
+func (f *HTTPFS) Open(name string) (fs.File, error) {
+	return f.OpenContext(context.Background(), name)
+}
+
+// OpenContext opens the named file, with requests scoped to ctx.
+//
+// It returns an error wrapping fs.ErrNotExist if the server responds with a
+// 404 or 410 status, in which case the cached file is removed.
+func (f *HTTPFS) OpenContext(ctx context.Context, name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	fail := func(err error) (fs.File, error) {
+		return nil, &fs.PathError{Op: "open", Path: f.base.String() + name, Err: err}
+	}
+
+	data, meta, cached := f.readCache(name)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.base.ResolveReference(&url.URL{Path: name}).String(), nil)
+	if err != nil {
+		return fail(err)
+	}
+	if cached {
+		if meta.ETag != "" {
+			req.Header.Set("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			req.Header.Set("If-Modified-Since", meta.LastModified)
+		}
+	}
+
+	client := f.Client
+	if client == nil {
+		client = defaultHTTPClient
+	}
+	// When the server is unreachable or fails, the cache is used as a
+	// snapshot of its files.
+	offline := func(err error) (fs.File, error) {
+		switch {
+		case f.cacheDir == "" || ctx.Err() != nil:
+			return fail(err)
+		case cached:
+			return openBytes(name, data, meta)
+		default:
+			return fail(fs.ErrNotExist)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return offline(err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return offline(err)
+		}
+		meta := httpCacheMeta{
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		}
+		// Caching is best-effort, since the file was fetched anyway.
+		_ = f.writeCache(name, body, meta)
+		return openBytes(name, body, meta)
+	case resp.StatusCode == http.StatusNotModified && cached:
+		return openBytes(name, data, meta)
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		f.removeCache(name)
+		return fail(fs.ErrNotExist)
+	case resp.StatusCode >= 500:
+		return offline(fmt.Errorf("unexpected HTTP status %s", resp.Status))
+	default:
+		return fail(fmt.Errorf("unexpected HTTP status %s", resp.Status))
+	}
+}
+
+func openBytes(name string, data []byte, meta httpCacheMeta) (fs.File, error) {
+	modTime, _ := http.ParseTime(meta.LastModified)
+	return newBytesFile(name, data, 0444, modTime), nil
+}
+
+// cachePaths returns the paths of the cached data and validators of the
+// named file.
+func (f *HTTPFS) cachePaths(name string) (string, string) {
+	p := filepath.Join(f.cacheDir, filepath.FromSlash(name))
+	dir, base := filepath.Split(p)
+	return p, filepath.Join(dir, "."+base+".http")
+}
+
+func (f *HTTPFS) readCache(name string) ([]byte, httpCacheMeta, bool) {
+	var meta httpCacheMeta
+	if f.cacheDir == "" {
+		return nil, meta, false
+	}
+	dataPath, metaPath := f.cachePaths(name)
+	data, err := os.ReadFile(dataPath)
+	if err != nil {
+		return nil, meta, false
+	}
+	// Without validators, the cached file is still usable as a fallback.
+	if raw, err := os.ReadFile(metaPath); err == nil {
+		_ = json.Unmarshal(raw, &meta)
+	}
+	return data, meta, true
+}
+
+func (f *HTTPFS) writeCache(name string, data []byte, meta httpCacheMeta) error {
+	if f.cacheDir == "" {
+		return nil
+	}
+	dataPath, metaPath := f.cachePaths(name)
+	if err := os.MkdirAll(filepath.Dir(dataPath), 0777); err != nil {
+		return err
+	}
+	raw, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	// The data is written first, so that stale validators at worst cause
+	// the file to be fetched again.
+	err = fsutil.WriteFile(dataPath, 0, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFile(metaPath, 0, func(w io.Writer) error {
+		_, err := w.Write(raw)
+		return err
+	})
+}
+
+func (f *HTTPFS) removeCache(name string) {
+	if f.cacheDir == "" {
+		return
+	}
+	dataPath, metaPath := f.cachePaths(name)
+	if err := os.Remove(dataPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	os.Remove(metaPath)
+}
+
+func (f *HTTPFS) String() string {
+	return strings.TrimSuffix(f.base.String(), "/")
+}
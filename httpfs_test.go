@@ -0,0 +1,164 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"snai.pe/boa/encoding"
+)
+
+func TestHTTPFS(t *testing.T) {
+	type Config struct {
+		Name string
+		Port int
+	}
+
+	var (
+		mu       sync.Mutex
+		files    = map[string]string{"/config/myapp.toml": "name = \"remote\"\nport = 8080\n"}
+		statuses []int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		status := http.StatusOK
+		defer func() { statuses = append(statuses, status) }()
+
+		data, ok := files[r.URL.Path]
+		if !ok {
+			status = http.StatusNotFound
+			http.NotFound(w, r)
+			return
+		}
+		etag := `"` + data[:4] + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			status = http.StatusNotModified
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(data))
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	remote, err := NewHTTPFS(server.URL+"/config", cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	load := func() (Config, error) {
+		var config Config
+		err := NewLoader().Paths(remote).Files("myapp").Load(&config)
+		return config, err
+	}
+	expect := func(expected Config, expectedStatus int) {
+		t.Helper()
+		config, err := load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config != expected {
+			t.Fatalf("expected %+v, got %+v", expected, config)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		found := false
+		for _, status := range statuses {
+			found = found || status == expectedStatus
+		}
+		if !found {
+			t.Fatalf("expected a response with status %d, got %v", expectedStatus, statuses)
+		}
+		statuses = nil
+	}
+
+	remoteConfig := Config{Name: "remote", Port: 8080}
+	expect(remoteConfig, http.StatusOK)
+
+	// The cached file is revalidated.
+	expect(remoteConfig, http.StatusNotModified)
+
+	f := Open("myapp", remote)
+	if err := f.Next(".toml"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if used := f.Used(); len(used) != 1 || used[0] != server.URL+"/config/myapp.toml" {
+		t.Fatalf("unexpected used files %q", used)
+	}
+
+	// The cached file is used when the server fails.
+	unreachable, err := NewHTTPFS("http://127.0.0.1:1/config", cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config Config
+	if err := NewLoader().Paths(unreachable).Files("myapp").Load(&config); err != nil {
+		t.Fatal(err)
+	}
+	if config != remoteConfig {
+		t.Fatalf("expected the cached config, got %+v", config)
+	}
+
+	// Files that are gone are removed from the cache.
+	mu.Lock()
+	delete(files, "/config/myapp.toml")
+	mu.Unlock()
+	expect(Config{}, http.StatusNotFound)
+	if _, err := remote.Open("myapp.toml"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected %v, got %v", fs.ErrNotExist, err)
+	}
+	if _, err := unreachable.Open("myapp.toml"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected %v, got %v", fs.ErrNotExist, err)
+	}
+
+	// Without a cache, failing to reach the server is an error.
+	uncached, err := NewHTTPFS("http://127.0.0.1:1/config", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uncached.Open("myapp.toml"); err == nil || errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected a connection error, got %v", err)
+	}
+}
+
+func TestHTTPFSContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	remote, err := NewHTTPFS(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var config struct{ Name string }
+	err = NewLoader().Paths(remote).Files("myapp").Option(encoding.WithContext(ctx)).Load(&config)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	if !strings.Contains(err.Error(), server.URL+"/myapp.") {
+		t.Fatalf("expected the error to name the URL, got %v", err)
+	}
+}
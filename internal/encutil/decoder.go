@@ -12,6 +12,7 @@ import (
 	"io/fs"
 	"os"
 	"reflect"
+	"strings"
 
 	"snai.pe/boa/encoding"
 	"snai.pe/boa/internal/reflectutil"
@@ -27,6 +28,88 @@ type UnmarshalerBase struct {
 	Extensions []string
 }
 
+// Env returns the description of how configuration values are looked up in
+// the environment.
+func (unmarshaler *UnmarshalerBase) Env() reflectutil.Env {
+	env := reflectutil.Env{
+		Lookup:    unmarshaler.LookupEnv,
+		Naming:    unmarshaler.EnvNamingConvention,
+		Separator: unmarshaler.EnvSeparator,
+
+		ListSeparator: unmarshaler.ListSeparator,
+	}
+	if unmarshaler.EnvCollections {
+		env.List = unmarshaler.ListEnv
+		if env.List == nil {
+			env.List = os.Environ
+		}
+	}
+	if unmarshaler.EnvFormat != "" && unmarshaler.EnvDecoder == nil {
+		env.Unmarshal = func(to reflect.Value, value string) error {
+			return fmt.Errorf("no known decoder for file extension %q", unmarshaler.EnvFormat)
+		}
+	} else if unmarshaler.EnvDecoder != nil {
+		env.Unmarshal = func(to reflect.Value, value string) error {
+			var doc *syntax.Document
+			if err := unmarshaler.EnvDecoder(strings.NewReader(value)).Decode(&doc); err != nil {
+				return err
+			}
+			return reflectutil.Unmarshal(to, doc.Root, unmarshaler.NamingConvention, unmarshaler.ListSeparator, true, unmarshaler.Self)
+		}
+	}
+	return env
+}
+
+// DocumentFile is implemented by virtual files that hold an already parsed
+// document rather than text, such as the ones of key-per-file directories.
+// The values of their string nodes are converted to the type of the
+// destination values, like environment variables are.
+type DocumentFile interface {
+	Document() (*syntax.Document, error)
+}
+
+// OverrideKey is the key of the entries of override documents, which sets
+// the value at a key path. Arg is the text that the entry was parsed from,
+// which names the entry in errors.
+type OverrideKey struct {
+	syntax.Node
+	Arg  string
+	Path []interface{}
+}
+
+// KeyPathComponents implements syntax.KeyPather.
+func (k *OverrideKey) KeyPathComponents() []interface{} { return k.Path }
+
+// override applies the entries of the override documents to val.
+func (unmarshaler *UnmarshalerBase) override(val reflect.Value) error {
+	self := reflectutil.StringScalars{Unmarshaler: unmarshaler.Self}
+	for _, doc := range unmarshaler.Overrides {
+		root, ok := doc.Root.(*syntax.Map)
+		if !ok {
+			return fmt.Errorf("override document has %T, but expected map instead", doc.Root)
+		}
+		for _, entry := range root.Entries {
+			var path []interface{}
+			switch key := entry.Key.(type) {
+			case syntax.KeyPather:
+				path = key.KeyPathComponents()
+			case *syntax.String:
+				path = []interface{}{key.Value}
+			default:
+				return fmt.Errorf("unsupported override key type %T", entry.Key)
+			}
+			err := reflectutil.Set(val, entry.Value, unmarshaler.NamingConvention, unmarshaler.ListSeparator, self, path...)
+			if err != nil {
+				if key, ok := entry.Key.(*OverrideKey); ok {
+					return fmt.Errorf("%s: %w", key.Arg, err)
+				}
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 type MultiFile interface {
 	Next(...string) error
 	File() fs.File
@@ -44,7 +127,17 @@ func (unmarshaler *UnmarshalerBase) Decode(in io.Reader, v interface{}) error {
 	}
 
 	decode := func(in io.Reader) error {
-		root, err := unmarshaler.NewParser(ctx, in).Parse()
+		var (
+			root *syntax.Document
+			err  error
+		)
+		self := unmarshaler.Self
+		if df, ok := in.(DocumentFile); ok {
+			root, err = df.Document()
+			self = reflectutil.StringScalars{Unmarshaler: self}
+		} else {
+			root, err = unmarshaler.NewParser(ctx, in).Parse()
+		}
 		if err != nil {
 			if e, ok := err.(*syntax.Error); ok {
 				e.Filename = Name(in)
@@ -55,7 +148,7 @@ func (unmarshaler *UnmarshalerBase) Decode(in io.Reader, v interface{}) error {
 			*node = root
 			return nil
 		}
-		err = reflectutil.Unmarshal(ptr.Elem(), root.Root, unmarshaler.NamingConvention, true, unmarshaler.Self)
+		err = reflectutil.Unmarshal(ptr.Elem(), root.Root, unmarshaler.NamingConvention, unmarshaler.ListSeparator, true, self)
 		if e, ok := err.(*encoding.LoadError); ok {
 			e.Filename = Name(in)
 		}
@@ -66,6 +159,7 @@ func (unmarshaler *UnmarshalerBase) Decode(in io.Reader, v interface{}) error {
 	if unmarshaler.LookupEnv == nil {
 		unmarshaler.LookupEnv = os.LookupEnv
 	}
+	env := unmarshaler.Env()
 
 	var names []string
 	if unmarshaler.EnvPrefix != "" {
@@ -88,15 +182,18 @@ func (unmarshaler *UnmarshalerBase) Decode(in io.Reader, v interface{}) error {
 				return err
 			}
 		}
-		_, err := reflectutil.PopulateFromEnv(ptr.Elem(), unmarshaler.AutomaticEnv, names, unmarshaler.LookupEnv)
-		return err
 	default:
 		if err := decode(f); err != nil {
 			return err
 		}
-		_, err := reflectutil.PopulateFromEnv(ptr.Elem(), unmarshaler.AutomaticEnv, names, unmarshaler.LookupEnv)
+	}
+	if _, err := reflectutil.PopulateFromEnv(ptr.Elem(), unmarshaler.AutomaticEnv, names, env); err != nil {
 		return err
 	}
+	if _, ok := v.(**syntax.Document); ok {
+		return nil
+	}
+	return unmarshaler.override(ptr.Elem())
 }
 
 func (unmarshaler *UnmarshalerBase) Option(opts ...interface{}) error {
@@ -0,0 +1,44 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package encutil
+
+import (
+	"snai.pe/boa/syntax"
+)
+
+// FirstTokens returns up to n of the first significant tokens of l, which
+// are the ones that are not whitespace, newlines, comments, or of the
+// specified types. The returned tokens stop at the first error or EOF
+// token, which is not included.
+//
+// It is used to detect the configuration language of inputs from their
+// first tokens, which may be the start of longer documents.
+func FirstTokens(l *syntax.Lexer, n int, skip ...syntax.TokenType) []syntax.Token {
+	tokens := make([]syntax.Token, 0, n)
+	for len(tokens) < n {
+		tok := l.Next()
+		switch tok.Type {
+		case syntax.TokenEOF, syntax.TokenError:
+			return tokens
+		case syntax.TokenWhitespace, syntax.TokenNewline, syntax.TokenComment, syntax.TokenInlineComment:
+			continue
+		}
+		if tok.IsAny(skip...) {
+			continue
+		}
+		tokens = append(tokens, tok)
+	}
+	return tokens
+}
+
+// TokenAt returns the type of the token at index i of tokens, or TokenEOF
+// if there is none.
+func TokenAt(tokens []syntax.Token, i int) syntax.TokenType {
+	if i >= len(tokens) {
+		return syntax.TokenEOF
+	}
+	return tokens[i].Type
+}
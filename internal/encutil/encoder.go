@@ -6,6 +6,7 @@
 package encutil
 
 import (
+	"bytes"
 	"fmt"
 	"go/constant"
 	"io"
@@ -30,6 +31,10 @@ type MarshalerBase struct {
 	Writer io.Writer
 	Self   Marshaler
 
+	// CommentPrefix is the prefix of line comments in the encoded language.
+	// It is used to comment out optional fields in templates.
+	CommentPrefix string
+
 	encoding.CommonOptions
 	encoding.EncoderOptions
 }
@@ -38,9 +43,95 @@ func (m *MarshalerBase) Encode(v interface{}) error {
 	if node, ok := v.(*syntax.Document); ok {
 		return syntax.MarshalDocument(node, m.Self)
 	}
+	if m.EncoderOptions.Template {
+		out := m.Writer
+		m.Writer = &commentWriter{Writer: out, prefix: m.CommentPrefix, bol: true}
+		defer func() { m.Writer = out }()
+	}
 	return reflectutil.Marshal(reflect.ValueOf(v), m.Self, m.NamingConvention)
 }
 
+// Template implements reflectutil.TemplateMarshaler.
+func (m *MarshalerBase) Template() (bool, string, reflectutil.Env) {
+	env := reflectutil.Env{
+		Naming:    m.EnvNamingConvention,
+		Separator: m.EnvSeparator,
+	}
+	return m.EncoderOptions.Template, m.EncoderOptions.EnvPrefix, env
+}
+
+// Comment implements reflectutil.TemplateMarshaler.
+func (m *MarshalerBase) Comment(on bool) {
+	cw, ok := m.Writer.(*commentWriter)
+	if !ok {
+		return
+	}
+	if on {
+		cw.depth++
+	} else {
+		cw.depth--
+	}
+}
+
+// commentWriter comments out the lines that are written while its depth is
+// positive, by inserting a comment prefix after their indentation. Lines that
+// already are comments are written as-is.
+type commentWriter struct {
+	io.Writer
+	prefix string
+	depth  int
+	bol    bool
+}
+
+func (w *commentWriter) Write(p []byte) (int, error) {
+	if w.depth <= 0 {
+		if len(p) > 0 {
+			w.bol = p[len(p)-1] == '\n'
+		}
+		return w.Writer.Write(p)
+	}
+
+	var n int
+	for len(p) > 0 {
+		i := 0
+		if w.bol {
+			for i < len(p) && (p[i] == ' ' || p[i] == '\t') {
+				i++
+			}
+			if i == len(p) {
+				break
+			}
+			if p[i] != '\n' && p[i] != '\r' && !strings.HasPrefix(w.prefix, string(p[i])) {
+				if _, err := w.Writer.Write(p[:i]); err != nil {
+					return n, err
+				}
+				if _, err := io.WriteString(w.Writer, w.prefix); err != nil {
+					return n, err
+				}
+				n, p, i = n+i, p[i:], 0
+			}
+			w.bol = false
+		}
+		if j := bytes.IndexByte(p[i:], '\n'); j >= 0 {
+			i += j + 1
+			w.bol = true
+		} else {
+			i = len(p)
+		}
+		written, err := w.Writer.Write(p[:i])
+		n += written
+		if err != nil {
+			return n, err
+		}
+		p = p[i:]
+	}
+	if len(p) > 0 {
+		written, err := w.Writer.Write(p)
+		return n + written, err
+	}
+	return n, nil
+}
+
 func (m *MarshalerBase) Option(handle func(interface{}) bool, opts ...interface{}) error {
 	for _, opt := range opts {
 		switch setopt := opt.(type) {
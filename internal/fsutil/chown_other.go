@@ -0,0 +1,16 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+//go:build windows || plan9 || js || wasip1
+
+package fsutil
+
+import (
+	"io/fs"
+)
+
+func chown(path string, info fs.FileInfo) error {
+	return nil
+}
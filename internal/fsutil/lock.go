@@ -0,0 +1,63 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package fsutil
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// errNoFlock is returned by flock when the system or filesystem does not
+// support it.
+var errNoFlock = errors.New("flock is not supported")
+
+// staleLock is the age after which a lock file is considered to be left over
+// by a process that crashed.
+const staleLock = time.Minute
+
+// Lock takes an exclusive advisory lock on the file at path, waiting for
+// other processes to release it, and returns the function that releases it.
+//
+// The lock is taken on a hidden lock file next to path rather than on path
+// itself, since WriteFile replaces path with a new file. It is taken with
+// flock(2) where supported, and otherwise by exclusively creating a lock
+// file, which is removed on unlock.
+func Lock(path string) (unlock func() error, err error) {
+	dir, base := filepath.Split(path)
+	name := filepath.Join(dir, "."+base+".lock")
+	unlock, err = flock(name)
+	if errors.Is(err, errNoFlock) {
+		return lockFile(filepath.Join(dir, "."+base+".lck"))
+	}
+	return unlock, err
+}
+
+// lockFile locks by exclusively creating the lock file at name. Lock files
+// older than staleLock are removed.
+func lockFile(name string) (func() error, error) {
+	delay := 5 * time.Millisecond
+	for {
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+		if err == nil {
+			f.Close()
+			return func() error { return os.Remove(name) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(name); err == nil && time.Since(info.ModTime()) > staleLock {
+			os.Remove(name)
+			continue
+		}
+		time.Sleep(delay)
+		if delay < 100*time.Millisecond {
+			delay *= 2
+		}
+	}
+}
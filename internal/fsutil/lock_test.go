@@ -0,0 +1,85 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLock(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.toml")
+
+	locks := map[string]func(string) (func() error, error){
+		"lock": Lock,
+		"lockfile": func(path string) (func() error, error) {
+			return lockFile(path + ".lck")
+		},
+	}
+	for name, lock := range locks {
+		t.Run(name, func(t *testing.T) {
+			var (
+				wg      sync.WaitGroup
+				holders int
+				max     int
+				mu      sync.Mutex
+			)
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					unlock, err := lock(path)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					mu.Lock()
+					holders++
+					if holders > max {
+						max = holders
+					}
+					mu.Unlock()
+					time.Sleep(time.Millisecond)
+					mu.Lock()
+					holders--
+					mu.Unlock()
+					if err := unlock(); err != nil {
+						t.Error(err)
+					}
+				}()
+			}
+			wg.Wait()
+			if max != 1 {
+				t.Fatalf("expected the lock to be held by one holder at most, got %d", max)
+			}
+		})
+	}
+}
+
+func TestLockFileStale(t *testing.T) {
+	name := filepath.Join(t.TempDir(), ".app.toml.lck")
+	if err := os.WriteFile(name, nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * staleLock)
+	if err := os.Chtimes(name, old, old); err != nil {
+		t.Fatal(err)
+	}
+	unlock, err := lockFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Fatalf("expected lock file to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+// Package fsutil implements the filesystem operations that boa shares
+// between its packages.
+package fsutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// WriteFile atomically replaces the file at path with the contents written
+// by write.
+//
+// The contents are written into a temporary file in the same directory,
+// synced to disk, then renamed over path, so that path is never left
+// truncated, even if the program crashes. If path already exists, its mode
+// and, where supported, its ownership are preserved, and if it is a symbolic
+// link, its target is replaced.
+//
+// If backups is 1, the replaced file is kept as path~. If backups is greater
+// than 1, the replaced files are kept as path.1 (the most recent) to
+// path.<backups>, and older ones are removed.
+func WriteFile(path string, backups int, write func(io.Writer) error) error {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+
+	perm := fs.FileMode(0666)
+	info, err := os.Stat(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		info = nil
+	case err != nil:
+		return err
+	default:
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := createTemp(path, perm)
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	err = write(tmp)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if err == nil && info != nil {
+		// The mode of new files is subject to the umask, and must be set
+		// explicitly to match the original one.
+		err = tmp.Chmod(perm)
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if info != nil {
+		// Ownership is preserved on a best-effort basis, as only privileged
+		// users may give files away.
+		_ = chown(tmpPath, info)
+	}
+
+	if info != nil && backups > 0 {
+		if err := backup(path, backups); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	syncDir(filepath.Dir(path))
+	return nil
+}
+
+// createTemp creates a new hidden file next to path, with the specified
+// permissions, before the umask. Unlike os.CreateTemp, which always uses
+// 0600, this gives new files the same permissions as os.Create.
+func createTemp(path string, perm fs.FileMode) (*os.File, error) {
+	dir, base := filepath.Split(path)
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano() + int64(os.Getpid())))
+	for i := 0; i < 10000; i++ {
+		name := filepath.Join(dir, "."+base+"."+strconv.FormatUint(uint64(rnd.Uint32()), 36)+".tmp")
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		return f, err
+	}
+	return nil, &fs.PathError{Op: "createtemp", Path: path, Err: fs.ErrExist}
+}
+
+// backup keeps a copy of the file at path, rotating the older copies.
+func backup(path string, backups int) error {
+	name := path + "~"
+	if backups > 1 {
+		for i := backups - 1; i > 0; i-- {
+			err := os.Rename(fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+1))
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+		name = path + ".1"
+	}
+
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	// The original file stays in place until it is replaced, so that path
+	// always exists.
+	if err := os.Link(path, name); err == nil {
+		return nil
+	}
+	return copyFile(name, path)
+}
+
+func copyFile(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// syncDir flushes the directory entries of dir to disk, so that renames
+// survive crashes. Errors are ignored, as not every system supports it.
+func syncDir(dir string) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = f.Sync()
+	_ = f.Close()
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package fsutil
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"testing"
+)
+
+func writeString(s string) func(io.Writer) error {
+	return func(w io.Writer) error {
+		_, err := io.WriteString(w, s)
+		return err
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.toml")
+
+	if err := WriteFile(path, 0, writeString("v1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(path, 0, writeString("v2")); err != nil {
+		t.Fatal(err)
+	}
+	if s := readFile(t, path); s != "v2" {
+		t.Fatalf("expected v2, got %q", s)
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0640 {
+			t.Fatalf("expected mode 0640, got %v", info.Mode().Perm())
+		}
+	}
+
+	// Failed writes leave the original file untouched.
+	fail := errors.New("failed")
+	err := WriteFile(path, 1, func(w io.Writer) error {
+		io.WriteString(w, "partial")
+		return fail
+	})
+	if !errors.Is(err, fail) {
+		t.Fatalf("expected %v, got %v", fail, err)
+	}
+	if s := readFile(t, path); s != "v2" {
+		t.Fatalf("expected v2, got %q", s)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected only app.toml, got %v", entries)
+	}
+}
+
+func TestWriteFileBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.toml")
+
+	for _, s := range []string{"v1", "v2", "v3"} {
+		if err := WriteFile(path, 1, writeString(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s := readFile(t, path+"~"); s != "v2" {
+		t.Fatalf("expected v2 in backup, got %q", s)
+	}
+
+	for _, s := range []string{"v4", "v5", "v6"} {
+		if err := WriteFile(path, 2, writeString(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s := readFile(t, path); s != "v6" {
+		t.Fatalf("expected v6, got %q", s)
+	}
+	if s := readFile(t, path+".1"); s != "v5" {
+		t.Fatalf("expected v5 in first backup, got %q", s)
+	}
+	if s := readFile(t, path+".2"); s != "v4" {
+		t.Fatalf("expected v4 in second backup, got %q", s)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	expected := []string{"app.toml", "app.toml.1", "app.toml.2", "app.toml~"}
+	if len(names) != len(expected) {
+		t.Fatalf("expected %q, got %q", expected, names)
+	}
+	for i := range names {
+		if names[i] != expected[i] {
+			t.Fatalf("expected %q, got %q", expected, names)
+		}
+	}
+}
+
+func TestWriteFileSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links require privileges on windows")
+	}
+	dir := t.TempDir()
+	target := filepath.Join(dir, "real.toml")
+	link := filepath.Join(dir, "app.toml")
+	if err := os.WriteFile(target, []byte("v1"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("real.toml", link); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(link, 0, writeString("v2")); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("expected %s to stay a symbolic link (%v)", link, err)
+	}
+	if s := readFile(t, target); s != "v2" {
+		t.Fatalf("expected v2, got %q", s)
+	}
+}
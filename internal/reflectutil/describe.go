@@ -0,0 +1,253 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package reflectutil
+
+import (
+	stdenc "encoding"
+	"fmt"
+	"math/big"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"snai.pe/boa/encoding"
+)
+
+// Description describes a single configuration key, for documentation
+// purposes.
+type Description struct {
+	// Path is the key path of the value, in the naming convention that was
+	// used to describe it.
+	Path []string
+
+	// Value is the current value of the key, which usually is its default.
+	Value reflect.Value
+
+	// Help contains the lines of the `help` tag of the field, if any.
+	Help []string
+
+	// Env lists the environment variables that PopulateFromEnv reads the
+	// value from, in order of precedence.
+	Env []string
+
+	// Allowed lists the textual representation of the allowed values, if
+	// they are known.
+	Allowed []string
+}
+
+// Describe walks the configuration value val and returns a description of
+// every key that it holds, in field order. Map entries are described in
+// lexical key order.
+//
+// Structs are described before their fields. Values that are not structs or
+// maps (including lists) are described as a whole, without descending into
+// their elements.
+//
+// The environment variables of each key are the ones PopulateFromEnv would
+// read with the specified automatic flag, prefix, and environment.
+func Describe(val reflect.Value, convention encoding.NamingConvention, parser interface{}, automatic bool, prefix string, env Env) []Description {
+	var names []string
+	if prefix != "" {
+		names = []string{prefix}
+	}
+	return describe(nil, val, nil, nil, convention, parser, automatic, names, env, nil)
+}
+
+func describe(out []Description, val reflect.Value, path, help []string, convention encoding.NamingConvention, parser interface{}, automatic bool, names []string, env Env, visiting []reflect.Type) []Description {
+	for {
+		if val.Kind() == reflect.Pointer && val.IsNil() {
+			for _, typ := range visiting {
+				if typ == val.Type() {
+					// Recursive type: describe the nil pointer as a whole
+					// rather than expanding it again.
+					desc := Description{Path: path, Value: val, Help: help}
+					if automatic {
+						desc.Env = names
+					}
+					return append(out, desc)
+				}
+			}
+			visiting = append(visiting[:len(visiting):len(visiting)], val.Type())
+			val = reflect.New(val.Type().Elem()).Elem()
+		} else if val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface && !val.IsNil() {
+			val = val.Elem()
+		} else {
+			break
+		}
+	}
+
+	desc := Description{Path: path, Value: val, Help: help}
+
+	typ := val.Type()
+	if IsValueType(typ) || IsValueType(reflect.PointerTo(typ)) {
+		if automatic {
+			desc.Env = names
+		}
+		return append(out, desc)
+	}
+
+	switch val.Kind() {
+	case reflect.Struct:
+		if len(path) > 0 {
+			out = append(out, desc)
+		}
+		fields, _ := VisibleFields(val, convention, parser)
+		for _, field := range fields {
+			fnames, fauto := env.field(field.Name, field.Options, names, automatic)
+			fpath := append(path[:len(path):len(path)], field.Options.Name)
+			out = describe(out, field.Value, fpath, field.Options.Help, field.Options.Naming, parser, fauto, fnames, env, visiting)
+		}
+		return out
+
+	case reflect.Map:
+		out = append(out, desc)
+
+		type entry struct {
+			key  string
+			elem reflect.Value
+		}
+		entries := make([]entry, 0, val.Len())
+		for _, k := range val.MapKeys() {
+			var key string
+			switch kval := k.Interface().(type) {
+			case encoding.TextMarshaler:
+				txt, err := kval.MarshalText()
+				if err != nil {
+					continue
+				}
+				key = string(txt)
+			case string:
+				key = kval
+			default:
+				continue
+			}
+			entries = append(entries, entry{key, val.MapIndex(k)})
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+		for _, e := range entries {
+			epath := append(path[:len(path):len(path)], e.key)
+			out = describe(out, e.elem, epath, nil, convention, parser, automatic, env.mapEntry(e.key, names), env, visiting)
+		}
+		return out
+
+	case reflect.Bool:
+		desc.Allowed = []string{"true", "false"}
+	}
+
+	if automatic {
+		desc.Env = names
+	}
+	return append(out, desc)
+}
+
+// TypeName returns a human-readable, language-independent name for typ.
+func TypeName(typ reflect.Type) string {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch typ {
+	case reflect.TypeOf(time.Duration(0)):
+		return "duration"
+	case reflect.TypeOf(time.Time{}):
+		return "date-time"
+	case reflect.TypeOf(url.URL{}):
+		return "URL"
+	case reflect.TypeOf(regexp.Regexp{}):
+		return "regular expression"
+	case reflect.TypeOf(big.Int{}):
+		return "integer"
+	case reflect.TypeOf(big.Float{}), reflect.TypeOf(big.Rat{}):
+		return "number"
+	case reflect.TypeOf([]byte(nil)):
+		return "string"
+	}
+	if IsValueType(typ) || IsValueType(reflect.PointerTo(typ)) {
+		return "string"
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Complex64, reflect.Complex128:
+		return "complex number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "list of " + TypeName(typ.Elem())
+	case reflect.Map:
+		return "map of " + TypeName(typ.Elem())
+	case reflect.Struct:
+		return "section"
+	}
+	return "any"
+}
+
+// FormatValue returns the textual representation of a default value, or
+// false if the value has no meaningful default.
+func FormatValue(v reflect.Value) (string, bool) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return "", false
+	}
+
+	iface := v.Interface()
+	if v.CanAddr() {
+		iface = v.Addr().Interface()
+	}
+	switch val := iface.(type) {
+	case stdenc.TextMarshaler:
+		txt, err := val.MarshalText()
+		if err != nil {
+			return "", false
+		}
+		return strconv.Quote(string(txt)), true
+	case *[]byte:
+		return strconv.Quote(string(*val)), true
+	case fmt.Stringer:
+		if v.Kind() != reflect.Struct {
+			return val.String(), true
+		}
+		return strconv.Quote(val.String()), true
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String()), true
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return fmt.Sprint(v.Interface()), true
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			return "", false
+		}
+		elems := make([]string, v.Len())
+		for i := range elems {
+			elem, ok := FormatValue(v.Index(i))
+			if !ok {
+				return "", false
+			}
+			elems[i] = elem
+		}
+		return "[" + strings.Join(elems, ", ") + "]", true
+	}
+	return "", false
+}
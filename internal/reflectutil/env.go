@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
@@ -36,7 +37,7 @@ func UnmarshalText(to reflect.Value, value string) (bool, error) {
 
 	case reflect.Pointer:
 		ptr := reflect.New(to.Type().Elem())
-		ok, err := UnmarshalText(ptr, value)
+		ok, err := UnmarshalText(ptr.Elem(), value)
 		if ok && err == nil {
 			to.Set(ptr)
 		}
@@ -90,19 +91,192 @@ func UnmarshalText(to reflect.Value, value string) (bool, error) {
 	return false, nil
 }
 
-func PopulateFromEnv(to reflect.Value, automatic bool, names []string, lookup func(string) (string, bool)) (bool, error) {
+// Env describes how configuration values are looked up in the environment.
+type Env struct {
+	// Lookup returns the value of the specified environment variable, and
+	// whether it is defined.
+	Lookup func(string) (string, bool)
+
+	// Naming is the naming convention of the keys that make up variable
+	// names. Defaults to SCREAMING_SNAKE_CASE.
+	Naming encoding.NamingConvention
+
+	// Separator is inserted between the prefix and the key of nested
+	// values. Defaults to "_".
+	Separator string
+
+	// ListSeparator separates the elements of lists that are set from a
+	// single variable. Defaults to os.PathListSeparator. It is overridden
+	// by the `sep` tag of the struct fields that hold the lists.
+	ListSeparator string
+
+	// List, if non-nil, returns all the environment variables in key=value
+	// form, like os.Environ. It is used to discover the variables of list
+	// elements and map entries that do not exist yet, which are then created.
+	List func() []string
+
+	// Unmarshal, if non-nil, sets to from a list, map, or struct that is
+	// written inline in the value of an environment variable, such as
+	// `[{host: "a", port: 1}]`.
+	Unmarshal func(to reflect.Value, value string) error
+}
 
-	if len(names) == 0 {
-		return true, nil
+func (env Env) listSeparator() string {
+	if env.ListSeparator == "" {
+		return string(os.PathListSeparator)
+	}
+	return env.ListSeparator
+}
+
+// fieldEnv returns the environment of the struct field with the specified
+// options.
+func (env Env) fieldEnv(opts FieldOpts) Env {
+	if opts.Sep != "" {
+		env.ListSeparator = opts.Sep
+	}
+	return env
+}
+
+// splitList splits value into the elements of a list, with
+// os.PathListSeparator if sep is empty. The empty string is the empty list.
+func splitList(value, sep string) []string {
+	if sep == "" {
+		sep = string(os.PathListSeparator)
+	}
+	if value == "" {
+		return []string{}
+	}
+	return strings.Split(value, sep)
+}
+
+// names returns the environment variable names formed by joining each of
+// the specified names to each of the keys, in order and without duplicates.
+func (env Env) names(names []string, keys ...string) []string {
+	sep := env.separator()
+	out := make([]string, 0, len(names)*len(keys))
+	seen := make(map[string]struct{}, cap(out))
+	for _, name := range names {
+		for _, key := range keys {
+			name := name + sep + key
+			if _, ok := seen[name]; ok {
+				continue
+			}
+			seen[name] = struct{}{}
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+func (env Env) separator() string {
+	if env.Separator == "" {
+		return "_"
+	}
+	return env.Separator
+}
+
+// children returns the distinct keys that immediately follow any of the
+// specified names in the names of the environment variables, in lexical
+// order. For instance, the children of PREFIX with PREFIX_A=1, PREFIX_B_C=2,
+// and PREFIX_B_D=3 are A and B.
+func (env Env) children(names []string) []string {
+	if env.List == nil || len(names) == 0 {
+		return nil
+	}
+	sep := env.separator()
+
+	var keys []string
+	seen := make(map[string]struct{})
+	for _, kv := range env.List() {
+		name := kv
+		if i := strings.IndexByte(kv, '='); i >= 0 {
+			name = kv[:i]
+		}
+		for _, prefix := range names {
+			if !strings.HasPrefix(name, prefix+sep) {
+				continue
+			}
+			key := name[len(prefix)+len(sep):]
+			if i := strings.Index(key, sep); i >= 0 {
+				key = key[:i]
+			}
+			if _, ok := seen[key]; ok || key == "" {
+				continue
+			}
+			seen[key] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (env Env) format(key string) string {
+	naming := env.Naming
+	if naming == nil {
+		naming = encoding.ScreamingSnakeCase
+	}
+	return naming.Format(key)
+}
+
+// field returns the environment variable names of the struct field with the
+// specified name and options, given the names of the struct, and whether the
+// field is populated automatically.
+func (env Env) field(name string, opts FieldOpts, names []string, automatic bool) ([]string, bool) {
+	switch {
+	case len(opts.Env) > 0:
+		return opts.Env, true
+	case opts.EnvPrefix != "":
+		return []string{opts.EnvPrefix}, true
 	}
+	return env.names(names, env.format(name)), automatic
+}
+
+// mapEntry returns the environment variable names of the map entry with the
+// specified key, given the names of the map.
+func (env Env) mapEntry(key string, names []string) []string {
+	toEnv := func(r rune) rune {
+		if !unicode.In(r, unicode.Letter, unicode.Digit) {
+			return '_'
+		}
+		return unicode.ToUpper(r)
+	}
+	return env.names(names, env.format(key), strings.Map(toEnv, key))
+}
+
+// isStructured returns whether value holds an inline list or map that must be
+// set on to with Env.Unmarshal.
+func isStructured(to reflect.Value, value string) bool {
+	switch to.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct, reflect.Interface:
+	default:
+		return false
+	}
+	if IsValueType(to.Type()) || IsValueType(reflect.PointerTo(to.Type())) {
+		return false
+	}
+	value = strings.TrimSpace(value)
+	return strings.HasPrefix(value, "[") || strings.HasPrefix(value, "{")
+}
+
+// PopulateFromEnv sets the parts of to that are defined in the environment.
+// names are the variables that to may be set from, in order of precedence,
+// and automatic reports whether they may be used; struct fields with an `env`
+// or `envPrefix` tag are always populated.
+func PopulateFromEnv(to reflect.Value, automatic bool, names []string, env Env) (bool, error) {
 
 	if to.Kind() == reflect.Pointer {
 		if !to.IsNil() {
-			return PopulateFromEnv(to.Elem(), automatic, names, lookup)
+			return PopulateFromEnv(to.Elem(), automatic, names, env)
+		}
+		if len(names) == 0 {
+			// Nothing can be set without a name; don't allocate values
+			// for nothing, or recurse endlessly on recursive types.
+			return false, nil
 		}
 
 		ptr := reflect.New(to.Type().Elem())
-		ok, err := PopulateFromEnv(ptr.Elem(), automatic, names, lookup)
+		ok, err := PopulateFromEnv(ptr.Elem(), automatic, names, env)
 		if ok && err == nil {
 			to.Set(ptr)
 		}
@@ -110,11 +284,23 @@ func PopulateFromEnv(to reflect.Value, automatic bool, names []string, lookup fu
 	}
 
 	var (
+		name    string
 		value   string
 		defined bool
 	)
 	for i := 0; i < len(names) && !defined; i++ {
-		value, defined = lookup(names[i])
+		name = names[i]
+		value, defined = env.Lookup(name)
+	}
+
+	changed := false
+	if automatic && defined && env.Unmarshal != nil && isStructured(to, value) {
+		if err := env.Unmarshal(to, value); err != nil {
+			return false, fmt.Errorf("%s: %w", name, err)
+		}
+		// The value has been consumed; more specific variables may still
+		// override parts of it.
+		changed, defined = true, false
 	}
 
 	if automatic && defined {
@@ -131,13 +317,14 @@ func PopulateFromEnv(to reflect.Value, automatic bool, names []string, lookup fu
 
 	case reflect.Slice, reflect.Array:
 		if defined {
-			list := strings.Split(value, string(os.PathListSeparator))
-			length := to.Len()
-			if len(list) > length && kind == reflect.Slice {
-				length = len(list)
-				to.Set(reflect.MakeSlice(to.Type(), length, length))
+			list := splitList(value, env.listSeparator())
+			if len(list) != to.Len() && kind == reflect.Slice {
+				to.Set(reflect.MakeSlice(to.Type(), len(list), len(list)))
 			}
-			for i := 0; i < length; i++ {
+			if len(list) > to.Len() {
+				return false, fmt.Errorf("%s: cannot set %d elements on list of length %d", name, len(list), to.Len())
+			}
+			for i := range list {
 				ok, err := UnmarshalText(to.Index(i), list[i])
 				if !ok && err == nil {
 					err = fmt.Errorf("cannot set element %d on list: %v cannot be populated from %q", i, to.Index(i).Type(), list[i])
@@ -146,9 +333,39 @@ func PopulateFromEnv(to reflect.Value, automatic bool, names []string, lookup fu
 					return false, err
 				}
 			}
+			changed = true
+		}
+
+		var indices []int
+		for _, key := range env.children(names) {
+			if i, err := strconv.Atoi(key); err == nil && i >= 0 && strconv.Itoa(i) == key {
+				indices = append(indices, i)
+			}
+		}
+		sort.Ints(indices)
+		if n := len(indices); n > 0 {
+			// Only allow as many new elements as there are indices, so that
+			// a typo in an index cannot make us allocate huge lists.
+			if last := indices[n-1]; last >= to.Len() {
+				if kind == reflect.Array || !to.CanSet() || last >= to.Len()+n {
+					return changed, fmt.Errorf("cannot set element %d on list of length %d from %s: index out of range", last, to.Len(), env.names(names, strconv.Itoa(last))[0])
+				}
+				grown := reflect.MakeSlice(to.Type(), last+1, last+1)
+				reflect.Copy(grown, to)
+				to.Set(grown)
+			}
 		}
+		for _, i := range indices {
+			ok, err := PopulateFromEnv(to.Index(i), automatic, env.names(names, strconv.Itoa(i)), env)
+			changed = changed || ok
+			if err != nil {
+				return changed, err
+			}
+		}
+		return changed, nil
 
 	case reflect.Map:
+		known := make(map[string]struct{})
 		for _, k := range to.MapKeys() {
 			var key string
 			switch kval := k.Interface().(type) {
@@ -171,56 +388,55 @@ func PopulateFromEnv(to reflect.Value, automatic bool, names []string, lookup fu
 			ptr.Elem().Set(elem)
 			elem = ptr.Elem()
 
-			toEnv := func(r rune) rune {
-				if !unicode.In(r, unicode.Letter, unicode.Digit) {
-					return '_'
-				}
-				return unicode.ToUpper(r)
+			enames := env.mapEntry(key, names)
+			for _, name := range enames {
+				known[name] = struct{}{}
 			}
 
-			formatted := encoding.ScreamingSnakeCase.Format(key)
-			asEnv := strings.Map(toEnv, key)
+			ok, err := PopulateFromEnv(elem, automatic, enames, env)
+			if err != nil {
+				return ok, err
+			}
+			if ok {
+				to.SetMapIndex(k, elem)
+				changed = true
+			}
+		}
 
-			uniq := map[string]struct{}{}
-			for _, name := range names {
-				uniq[name+"_"+formatted] = struct{}{}
-				uniq[name+"_"+asEnv] = struct{}{}
+		for _, key := range env.children(names) {
+			enames := env.names(names, key)
+			if _, ok := known[enames[0]]; ok {
+				continue
 			}
-			tentatives := make([]string, 0, len(uniq))
-			for name := range uniq {
-				tentatives = append(tentatives, name)
+
+			k := reflect.New(to.Type().Key()).Elem()
+			if ok, err := UnmarshalText(k, key); !ok || err != nil {
+				// The key cannot be represented in the map.
+				continue
 			}
-			ok, err := PopulateFromEnv(elem, automatic, tentatives, lookup)
+			elem := reflect.New(to.Type().Elem()).Elem()
+			if existing := to.MapIndex(k); existing.IsValid() {
+				elem.Set(existing)
+			}
+			ok, err := PopulateFromEnv(elem, automatic, enames, env)
 			if err != nil {
 				return ok, err
 			}
 			if ok {
+				if to.IsNil() {
+					to.Set(reflect.MakeMap(to.Type()))
+				}
 				to.SetMapIndex(k, elem)
+				changed = true
 			}
 		}
+		return changed, nil
 
 	case reflect.Struct:
-		changed := false
 		fields, _ := VisibleFields(to, encoding.ScreamingSnakeCase, nil)
 		for _, field := range fields {
-			var (
-				ok  bool
-				err error
-			)
-			if field.Options.Env != "" {
-				ok, err = PopulateFromEnv(field.Value, true, []string{field.Options.Env}, lookup)
-			} else {
-				key := encoding.ScreamingSnakeCase.Format(field.Name)
-				uniq := map[string]struct{}{}
-				for _, name := range names {
-					uniq[name+"_"+key] = struct{}{}
-				}
-				tentatives := make([]string, 0, len(uniq))
-				for name := range uniq {
-					tentatives = append(tentatives, name)
-				}
-				ok, err = PopulateFromEnv(field.Value, automatic, tentatives, lookup)
-			}
+			fnames, fauto := env.field(field.Name, field.Options, names, automatic)
+			ok, err := PopulateFromEnv(field.Value, fauto, fnames, env.fieldEnv(field.Options))
 			changed = changed || ok
 			if err != nil {
 				return changed, err
@@ -229,5 +445,149 @@ func PopulateFromEnv(to reflect.Value, automatic bool, names []string, lookup fu
 		return changed, nil
 	}
 
-	return false, nil
+	return changed, nil
+}
+
+// EnvVar describes an environment variable that PopulateFromEnv reads.
+type EnvVar struct {
+	// Name is the name of the variable.
+	Name string
+
+	// Path is the path of the value that the variable sets, made of struct
+	// field names and map keys.
+	Path []string
+
+	// Type is the type of the value that the variable sets.
+	Type reflect.Type
+
+	// Help contains the lines of the `help` tag of the field, if any.
+	Help []string
+
+	// Explicit is true if the variable is named by an `env` tag, rather than
+	// derived from the field path.
+	Explicit bool
+
+	// Value is the current value that the variable sets, or the invalid
+	// Value if it is unset because of a nil pointer.
+	Value reflect.Value
+
+	// ListSeparator separates the elements of the value if it is a list.
+	ListSeparator string
+}
+
+// EnvVars returns the environment variables that PopulateFromEnv reads when
+// called with the same arguments, in the order that it reads them.
+func EnvVars(val reflect.Value, automatic bool, names []string, env Env) []EnvVar {
+	return envVars(nil, val, nil, nil, automatic, false, names, env, nil)
+}
+
+func envVars(out []EnvVar, val reflect.Value, path, help []string, automatic, explicit bool, names []string, env Env, visiting []reflect.Type) []EnvVar {
+	if val.Kind() == reflect.Pointer {
+		if !val.IsNil() {
+			return envVars(out, val.Elem(), path, help, automatic, explicit, names, env, visiting)
+		}
+		if len(names) == 0 {
+			return out
+		}
+		for _, typ := range visiting {
+			if typ == val.Type() {
+				return out
+			}
+		}
+		visiting = append(visiting[:len(visiting):len(visiting)], val.Type())
+		return envVars(out, reflect.New(val.Type().Elem()).Elem(), path, help, automatic, explicit, names, env, visiting)
+	}
+
+	leaf := func() []EnvVar {
+		if !automatic {
+			return out
+		}
+		value := val
+		if len(visiting) > 0 {
+			// Only nil pointers are visited; the value is a placeholder.
+			value = reflect.Value{}
+		}
+		for _, name := range names {
+			out = append(out, EnvVar{
+				Name:     name,
+				Path:     path,
+				Type:     val.Type(),
+				Help:     help,
+				Explicit: explicit,
+				Value:    value,
+
+				ListSeparator: env.listSeparator(),
+			})
+		}
+		return out
+	}
+
+	if automatic && canUnmarshalText(val.Type()) {
+		return leaf()
+	}
+
+	for val.Kind() == reflect.Interface {
+		val = val.Elem()
+	}
+
+	switch val.Kind() {
+	case reflect.Slice, reflect.Array:
+		return leaf()
+
+	case reflect.Map:
+		keys := val.MapKeys()
+		entries := make([]string, 0, len(keys))
+		elems := make(map[string]reflect.Value, len(keys))
+		for _, k := range keys {
+			var key string
+			switch kval := k.Interface().(type) {
+			case encoding.TextMarshaler:
+				txt, err := kval.MarshalText()
+				if err != nil {
+					continue
+				}
+				key = string(txt)
+			case string:
+				key = kval
+			default:
+				continue
+			}
+			entries = append(entries, key)
+			elems[key] = val.MapIndex(k)
+		}
+		sort.Strings(entries)
+
+		for _, key := range entries {
+			epath := append(path[:len(path):len(path)], key)
+			out = envVars(out, elems[key], epath, nil, automatic, explicit, env.mapEntry(key, names), env, visiting)
+		}
+
+	case reflect.Struct:
+		fields, _ := VisibleFields(val, encoding.ScreamingSnakeCase, nil)
+		for _, field := range fields {
+			fnames, fauto := env.field(field.Name, field.Options, names, automatic)
+			fpath := append(path[:len(path):len(path)], field.Name)
+			out = envVars(out, field.Value, fpath, field.Options.Help, fauto, len(field.Options.Env) > 0, fnames, env.fieldEnv(field.Options), visiting)
+		}
+	}
+	return out
+}
+
+// canUnmarshalText returns whether UnmarshalText can set values of the
+// specified type.
+func canUnmarshalText(typ reflect.Type) bool {
+	if reflect.PointerTo(typ).Implements(reflect.TypeOf((*stdenc.TextUnmarshaler)(nil)).Elem()) {
+		return true
+	}
+	if typ == reflect.TypeOf([]byte(nil)) {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.Bool, reflect.String, reflect.Interface,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	}
+	return false
 }
@@ -27,13 +27,30 @@ type FieldOpts struct {
 	Ignore bool
 	Naming NamingConvention
 	Inline bool
-	Env    string
+
+	// Env lists the environment variables that the field is populated
+	// from, in order of precedence.
+	Env []string
+
+	// EnvPrefix, if set, replaces the environment variable prefix of the
+	// fields of a nested struct.
+	EnvPrefix string
+
+	// Sep, if set, separates the elements of the lists within the field
+	// that are set from a single string.
+	Sep string
 }
 
 type MapEntry struct {
 	Key     string
 	Value   reflect.Value
 	Options FieldOpts
+
+	// Optional is set on the struct fields of configuration templates whose
+	// value is nil, and that are written commented out.
+	Optional bool
+
+	tmpl *template
 }
 
 type Stringifier interface {
@@ -89,7 +106,115 @@ type NilMarshaler interface {
 	MarshalNil() error
 }
 
+// TemplateMarshaler is an optional interface that Marshaler implementations
+// may satisfy to write configuration templates, in which every struct field is
+// annotated with its type, default, and environment variables, and optional
+// fields are commented out.
+type TemplateMarshaler interface {
+	// Template returns whether a configuration template must be written,
+	// along with the prefix and naming of the environment variables that
+	// are used to populate the configuration.
+	Template() (bool, string, Env)
+
+	// Comment starts a commented out section if on is true, or ends it
+	// otherwise. Sections may be nested.
+	Comment(on bool)
+}
+
+// template holds the state needed to annotate the fields of a configuration
+// template with the environment variables that PopulateFromEnv reads.
+type template struct {
+	marshaler TemplateMarshaler
+	env       Env
+	names     []string
+	automatic bool
+
+	// visiting holds the pointer types that were expanded from nil values
+	// on the way to the current value, to stop at recursive types.
+	visiting []reflect.Type
+}
+
+func (t *template) field(field reflect.StructField, opts FieldOpts) *template {
+	if t == nil {
+		return nil
+	}
+	names, automatic := t.env.field(field.Name, opts, t.names, t.automatic)
+	return &template{t.marshaler, t.env, names, automatic, t.visiting}
+}
+
+func (t *template) mapEntry(key string) *template {
+	if t == nil {
+		return nil
+	}
+	return &template{t.marshaler, t.env, t.env.mapEntry(key, t.names), t.automatic, t.visiting}
+}
+
+func (t *template) elem() *template {
+	if t == nil {
+		return nil
+	}
+	return &template{marshaler: t.marshaler, env: t.env, visiting: t.visiting}
+}
+
+// annotate marks the specified struct field entry as optional if its value
+// is nil, and adds its type, default, and environment variables to its help
+// text. Nil pointers are expanded to zero values, unless their type is
+// already being expanded, in which case they are left nil.
+func (t *template) annotate(kv *MapEntry) {
+	switch kv.Value.Kind() {
+	case reflect.Pointer:
+		if kv.Value.IsNil() {
+			kv.Optional = true
+			if !t.isVisiting(kv.Value.Type()) {
+				t.visiting = append(t.visiting[:len(t.visiting):len(t.visiting)], kv.Value.Type())
+				kv.Value = reflect.New(kv.Value.Type().Elem()).Elem()
+			}
+		}
+	case reflect.Map:
+		if kv.Value.IsNil() {
+			kv.Optional = true
+			kv.Value = reflect.MakeMap(kv.Value.Type())
+		}
+	case reflect.Slice, reflect.Interface:
+		kv.Optional = kv.Value.IsNil()
+	}
+
+	help := make([]string, len(kv.Options.Help), len(kv.Options.Help)+3)
+	copy(help, kv.Options.Help)
+	help = append(help, "Type: "+TypeName(kv.Value.Type()))
+	if def, ok := FormatValue(kv.Value); ok && !kv.Optional {
+		help = append(help, "Default: "+def)
+	}
+	if kv.tmpl.automatic && len(kv.tmpl.names) > 0 && kv.Value.Kind() != reflect.Struct && kv.Value.Kind() != reflect.Map {
+		help = append(help, "Environment: "+strings.Join(kv.tmpl.names, ", "))
+	}
+	kv.Options.Help = help
+}
+
+func (t *template) isVisiting(typ reflect.Type) bool {
+	for _, visited := range t.visiting {
+		if visited == typ {
+			return true
+		}
+	}
+	return false
+}
+
 func Marshal(val reflect.Value, marshaler Marshaler, convention NamingConvention) error {
+	var tmpl *template
+	if tm, ok := marshaler.(TemplateMarshaler); ok {
+		if enabled, prefix, env := tm.Template(); enabled {
+			tmpl = &template{marshaler: tm, env: env}
+			if prefix != "" {
+				tmpl.names = []string{prefix}
+				tmpl.automatic = true
+			}
+		}
+	}
+	return marshal(val, marshaler, convention, tmpl)
+}
+
+func marshal(val reflect.Value, marshaler Marshaler, convention NamingConvention, tmpl *template) error {
 	typ := val.Type()
 
 	if ok, err := marshaler.MarshalValue(val); ok || err != nil {
@@ -163,7 +288,7 @@ func Marshal(val reflect.Value, marshaler Marshaler, convention NamingConvention
 			}
 			return m.MarshalNil()
 		}
-		return Marshal(val.Elem(), marshaler, convention)
+		return marshal(val.Elem(), marshaler, convention, tmpl)
 
 	case reflect.Bool:
 		return marshaler.MarshalBool(val.Bool())
@@ -206,7 +331,7 @@ func Marshal(val reflect.Value, marshaler Marshaler, convention NamingConvention
 			if ok, err := marshaler.MarshalListElem(val, elem, i); ok || err != nil {
 				return err
 			}
-			if err := Marshal(elem, marshaler, convention); err != nil {
+			if err := marshal(elem, marshaler, convention, tmpl.elem()); err != nil {
 				return err
 			}
 			if post, ok := marshaler.(PostListElemMarshaler); ok {
@@ -277,7 +402,7 @@ func Marshal(val reflect.Value, marshaler Marshaler, convention NamingConvention
 			} else if ok {
 				continue
 			}
-			if err := Marshal(kv.Value, marshaler, convention); err != nil {
+			if err := marshal(kv.Value, marshaler, convention, tmpl.mapEntry(kv.Key)); err != nil {
 				return err
 			}
 			if post, ok := marshaler.(PostMapValueMarshaler); ok {
@@ -295,6 +420,13 @@ func Marshal(val reflect.Value, marshaler Marshaler, convention NamingConvention
 
 	case reflect.Struct:
 		kvs := VisibleFieldsAsMapEntries(val, convention, marshaler)
+		if tmpl != nil {
+			layout := getLayout(val.Type(), convention, marshaler)
+			for i := range kvs {
+				kvs[i].tmpl = tmpl.field(layout.fields[i].StructField, kvs[i].Options)
+				kvs[i].tmpl.annotate(&kvs[i])
+			}
+		}
 
 		if ok, err := marshaler.MarshalMap(val, kvs); ok || err != nil {
 			return err
@@ -309,21 +441,14 @@ func Marshal(val reflect.Value, marshaler Marshaler, convention NamingConvention
 				}
 			}
 
-			if err := marshaler.MarshalMapKey(val, kv, i); err != nil {
-				return err
-			}
-			if ok, err := marshaler.MarshalMapValue(val, kv, i); err != nil {
-				return err
-			} else if ok {
-				continue
+			if kv.Optional {
+				tmpl.marshaler.Comment(true)
 			}
-			if err := Marshal(kv.Value, marshaler, kv.Options.Naming); err != nil {
+			if err := marshalStructValue(val, kv, i, marshaler); err != nil {
 				return err
 			}
-			if post, ok := marshaler.(PostStructValueMarshaler); ok {
-				if err := post.MarshalStructValuePost(val, kv, i); err != nil {
-					return err
-				}
+			if kv.Optional {
+				tmpl.marshaler.Comment(false)
 			}
 		}
 		if post, ok := marshaler.(PostMapMarshaler); ok {
@@ -337,6 +462,26 @@ func Marshal(val reflect.Value, marshaler Marshaler, convention NamingConvention
 	return fmt.Errorf("cannot marshal %v: unsupported type %v", val.Interface(), typ)
 }
 
+func marshalStructValue(val reflect.Value, kv MapEntry, i int, marshaler Marshaler) error {
+	if err := marshaler.MarshalMapKey(val, kv, i); err != nil {
+		return err
+	}
+	if ok, err := marshaler.MarshalMapValue(val, kv, i); err != nil {
+		return err
+	} else if ok {
+		return nil
+	}
+	if err := marshal(kv.Value, marshaler, kv.Options.Naming, kv.tmpl); err != nil {
+		return err
+	}
+	if post, ok := marshaler.(PostStructValueMarshaler); ok {
+		if err := post.MarshalStructValuePost(val, kv, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // BytesToString converts the provided byte slice into a string. No copy is performed,
 // which means that changing the data slice will also change the string, which will
 // break any code that relies on the assumption that strings are read-only. Use
@@ -416,8 +561,18 @@ func ParseFieldOpts(tag reflect.StructTag, marshaler interface{}, convention Nam
 	if opts.Naming == nil {
 		opts.Naming = convention
 	}
-	if env, ok := LookupTag(tag, "env", false); ok {
-		opts.Env = env.Value
+	if env, ok := LookupTag(tag, "env", true); ok {
+		for _, name := range append([]string{env.Value}, env.Options...) {
+			if name = strings.TrimSpace(name); name != "" {
+				opts.Env = append(opts.Env, name)
+			}
+		}
+	}
+	if prefix, ok := LookupTag(tag, "envPrefix", false); ok {
+		opts.EnvPrefix = prefix.Value
+	}
+	if sep, ok := LookupTag(tag, "sep", false); ok {
+		opts.Sep = sep.Value
 	}
 	return
 }
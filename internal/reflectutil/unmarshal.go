@@ -25,6 +25,75 @@ type Unmarshaler interface {
 
 type UnmarshalFunc func(val reflect.Value, node syntax.Value) (bool, error)
 
+// UnmarshalString converts the value of a string node into the type of val,
+// like the values of environment variables are. It is meant for formats that
+// only have string values, and returns false if node is not a string, or if
+// val is a string or an interface. Empty strings set pointers to nil.
+func UnmarshalString(val reflect.Value, node syntax.Value) (bool, error) {
+	str, ok := node.(*syntax.String)
+	if !ok {
+		return false, nil
+	}
+	switch val.Kind() {
+	case reflect.String, reflect.Interface:
+		return false, nil
+	case reflect.Pointer:
+		if str.Value == "" {
+			val.Set(reflect.Zero(val.Type()))
+			return true, nil
+		}
+	}
+	return UnmarshalText(val, str.Value)
+}
+
+// StringScalars wraps an Unmarshaler, so that the values of string nodes are
+// converted with UnmarshalString when the wrapped Unmarshaler does not
+// handle them, and that number and boolean nodes set on strings take the
+// text of their tokens. Struct tags are parsed by the wrapped Unmarshaler.
+type StringScalars struct {
+	Unmarshaler
+}
+
+func (u StringScalars) UnmarshalValue(val reflect.Value, node syntax.Value) (bool, error) {
+	if ok, err := u.Unmarshaler.UnmarshalValue(val, node); ok || err != nil {
+		return ok, err
+	}
+	switch node.(type) {
+	case *syntax.Number, *syntax.Bool:
+		if val.Kind() != reflect.String {
+			break
+		}
+		var text strings.Builder
+		for _, tok := range node.Base().Tokens {
+			if !tok.IsAny(syntax.TokenWhitespace, syntax.TokenNewline, syntax.TokenComment, syntax.TokenInlineComment) {
+				text.WriteString(tok.Raw)
+			}
+		}
+		if text.Len() == 0 {
+			break
+		}
+		val.SetString(text.String())
+		return true, nil
+	}
+	return UnmarshalString(val, node)
+}
+
+func (u StringScalars) ParseStructTag(tag reflect.StructTag) (FieldOpts, bool) {
+	if parser, ok := u.Unmarshaler.(StructTagParser); ok {
+		return parser.ParseStructTag(tag)
+	}
+	return FieldOpts{}, false
+}
+
+// fieldSep returns the separator of the lists within a struct field that are
+// written as a single string, given the separator of its parent.
+func fieldSep(sep string, opts FieldOpts) string {
+	if opts.Sep != "" {
+		return opts.Sep
+	}
+	return sep
+}
+
 // isHashable reports whether v can be used as a Go map key.
 func isHashable(v reflect.Value) bool {
 	switch v.Kind() {
@@ -65,15 +134,15 @@ func resolveAlias(v syntax.Value) syntax.Value {
 	}
 }
 
-func Unmarshal(val reflect.Value, node syntax.Value, convention encoding.NamingConvention, merge bool, unmarshaler Unmarshaler) error {
-	_, err := unmarshal(val, node, convention, nil, merge, unmarshaler)
+func Unmarshal(val reflect.Value, node syntax.Value, convention encoding.NamingConvention, sep string, merge bool, unmarshaler Unmarshaler) error {
+	_, err := unmarshal(val, node, convention, sep, nil, merge, unmarshaler)
 	return err
 }
 
-func unmarshal(val reflect.Value, node syntax.Value, convention encoding.NamingConvention, path []string, merge bool, unmarshaler Unmarshaler) (reflect.Value, error) {
+func unmarshal(val reflect.Value, node syntax.Value, convention encoding.NamingConvention, sep string, path []string, merge bool, unmarshaler Unmarshaler) (reflect.Value, error) {
 	// Transparently resolve YAML aliases to their target values.
 	if alias, ok := node.(*syntax.Alias); ok {
-		return unmarshal(val, alias.Target, convention, path, merge, unmarshaler)
+		return unmarshal(val, alias.Target, convention, sep, path, merge, unmarshaler)
 	}
 
 	typ := val.Type()
@@ -148,7 +217,7 @@ func unmarshal(val reflect.Value, node syntax.Value, convention encoding.NamingC
 		}
 
 		if recurse {
-			_, err := unmarshal(rval, node, convention, path, merge, unmarshaler)
+			_, err := unmarshal(rval, node, convention, sep, path, merge, unmarshaler)
 			if err != nil {
 				return rval, err
 			}
@@ -163,7 +232,7 @@ func unmarshal(val reflect.Value, node syntax.Value, convention encoding.NamingC
 	}
 
 	if scalar, err := SetScalar(val, node); scalar {
-		return val, err
+		return val, newErr(err)
 	}
 
 	switch kind := val.Kind(); kind {
@@ -176,7 +245,7 @@ func unmarshal(val reflect.Value, node syntax.Value, convention encoding.NamingC
 			if val.IsNil() {
 				val.Set(reflect.New(typ.Elem()))
 			}
-			if _, err := unmarshal(val.Elem(), node, convention, path, merge, unmarshaler); err != nil {
+			if _, err := unmarshal(val.Elem(), node, convention, sep, path, merge, unmarshaler); err != nil {
 				return val, err
 			}
 		}
@@ -186,6 +255,28 @@ func unmarshal(val reflect.Value, node syntax.Value, convention encoding.NamingC
 			val.Set(reflect.Zero(typ))
 			return val, nil
 		}
+		if str, ok := node.(*syntax.String); ok && sep != "" {
+			// Lists may be written as a single string when a separator is
+			// set, whose elements are converted like the ones of
+			// environment variables.
+			items := splitList(str.Value, sep)
+			if kind == reflect.Slice && val.Len() != len(items) {
+				val.Set(reflect.MakeSlice(typ, len(items), len(items)))
+			}
+			if len(items) > val.Len() {
+				return val, newErr(fmt.Errorf("cannot set %d elements on list of length %d", len(items), val.Len()))
+			}
+			for idx, item := range items {
+				ok, err := UnmarshalText(val.Index(idx), item)
+				if !ok && err == nil {
+					err = fmt.Errorf("%v cannot be converted from %q", val.Index(idx).Type(), item)
+				}
+				if err != nil {
+					return val, newErr(fmt.Errorf("cannot set element %d on list: %w", idx, err))
+				}
+			}
+			return val, nil
+		}
 		list, ok := node.(*syntax.List)
 		if !ok {
 			return val, newNodeErr("list")
@@ -200,7 +291,7 @@ func unmarshal(val reflect.Value, node syntax.Value, convention encoding.NamingC
 			if idx >= val.Len() && kind == reflect.Array {
 				return val, newErr(fmt.Errorf("cannot assign value to index %d: index out of bounds", idx))
 			}
-			if _, err := unmarshal(val.Index(idx), item, convention, append(path, fmt.Sprintf("[%d]", idx)), merge, unmarshaler); err != nil {
+			if _, err := unmarshal(val.Index(idx), item, convention, sep, append(path, fmt.Sprintf("[%d]", idx)), merge, unmarshaler); err != nil {
 				return val, err
 			}
 		}
@@ -225,11 +316,11 @@ func unmarshal(val reflect.Value, node syntax.Value, convention encoding.NamingC
 				for _, e := range at {
 					p = append(p, fmt.Sprintf("[%v]", e))
 				}
-				if err := Set(val, entry.Value, convention, unmarshaler, at...); err != nil {
+				if err := Set(val, entry.Value, convention, sep, unmarshaler, at...); err != nil {
 					return val, err
 				}
 			default:
-				rkey, err := unmarshal(reflect.New(typ.Key()).Elem(), entry.Key, convention, path, merge, unmarshaler)
+				rkey, err := unmarshal(reflect.New(typ.Key()).Elem(), entry.Key, convention, sep, path, merge, unmarshaler)
 				if err != nil {
 					return val, err
 				}
@@ -246,7 +337,7 @@ func unmarshal(val reflect.Value, node syntax.Value, convention encoding.NamingC
 				}
 				set := !mval.IsValid()
 
-				rval, err = unmarshal(rval, entry.Value, convention, append(path, fmt.Sprintf("[%v]", rkey.Interface())), merge, unmarshaler)
+				rval, err = unmarshal(rval, entry.Value, convention, sep, append(path, fmt.Sprintf("[%v]", rkey.Interface())), merge, unmarshaler)
 				if err != nil {
 					return val, err
 				}
@@ -274,7 +365,7 @@ func unmarshal(val reflect.Value, node syntax.Value, convention encoding.NamingC
 				for _, e := range at {
 					path = append(path, fmt.Sprintf("[%v]", e))
 				}
-				if err := Set(val, entry.Value, convention, unmarshaler, at...); err != nil {
+				if err := Set(val, entry.Value, convention, sep, unmarshaler, at...); err != nil {
 					return val, err
 				}
 			case *syntax.String:
@@ -282,7 +373,7 @@ func unmarshal(val reflect.Value, node syntax.Value, convention encoding.NamingC
 				if !ok {
 					continue
 				}
-				_, err := unmarshal(field.Value, entry.Value, field.Options.Naming, append(path, fmt.Sprintf(".%v", field.Name)), merge, unmarshaler)
+				_, err := unmarshal(field.Value, entry.Value, field.Options.Naming, fieldSep(sep, field.Options), append(path, fmt.Sprintf(".%v", field.Name)), merge, unmarshaler)
 				if err != nil {
 					return val, err
 				}
@@ -297,7 +388,7 @@ func unmarshal(val reflect.Value, node syntax.Value, convention encoding.NamingC
 				if !ok {
 					continue
 				}
-				_, err := unmarshal(field.Value, entry.Value, field.Options.Naming, append(path, fmt.Sprintf(".%v", field.Name)), merge, unmarshaler)
+				_, err := unmarshal(field.Value, entry.Value, field.Options.Naming, fieldSep(sep, field.Options), append(path, fmt.Sprintf(".%v", field.Name)), merge, unmarshaler)
 				if err != nil {
 					return val, err
 				}
@@ -306,7 +397,7 @@ func unmarshal(val reflect.Value, node syntax.Value, convention encoding.NamingC
 				if !ok {
 					continue
 				}
-				_, err := unmarshal(field.Value, entry.Value, field.Options.Naming, append(path, fmt.Sprintf(".%v", field.Name)), merge, unmarshaler)
+				_, err := unmarshal(field.Value, entry.Value, field.Options.Naming, fieldSep(sep, field.Options), append(path, fmt.Sprintf(".%v", field.Name)), merge, unmarshaler)
 				if err != nil {
 					return val, err
 				}
@@ -317,7 +408,7 @@ func unmarshal(val reflect.Value, node syntax.Value, convention encoding.NamingC
 
 	case reflect.Interface:
 		if merge && !val.IsNil() {
-			if _, err := unmarshal(val.Elem(), node, convention, path, merge, unmarshaler); err != nil {
+			if _, err := unmarshal(val.Elem(), node, convention, sep, path, merge, unmarshaler); err != nil {
 				return val, err
 			}
 		} else {
@@ -360,7 +451,7 @@ func (e *pathError) Unwrap() error {
 	return e.Err
 }
 
-func Set(val reflect.Value, node syntax.Value, convention encoding.NamingConvention, unmarshaler Unmarshaler, at ...interface{}) (outerr error) {
+func Set(val reflect.Value, node syntax.Value, convention encoding.NamingConvention, sep string, unmarshaler Unmarshaler, at ...interface{}) (outerr error) {
 	if !val.IsValid() {
 		panic("cannot call Set with invalid value")
 	}
@@ -383,11 +474,11 @@ func Set(val reflect.Value, node syntax.Value, convention encoding.NamingConvent
 		if val.IsNil() {
 			val.Set(reflect.New(val.Type().Elem()))
 		}
-		return Set(val.Elem(), node, convention, unmarshaler, at...)
+		return Set(val.Elem(), node, convention, sep, unmarshaler, at...)
 	}
 
 	if len(at) == 0 {
-		newval, err := unmarshal(val, node, convention, nil, true, unmarshaler)
+		newval, err := unmarshal(val, node, convention, sep, nil, true, unmarshaler)
 		if err == nil {
 			val.Set(newval)
 		}
@@ -417,7 +508,7 @@ func Set(val reflect.Value, node syntax.Value, convention encoding.NamingConvent
 			rval.Set(reflect.New(typ.Elem()))
 			val.Set(rval)
 		}
-		return Set(rval.Elem(), node, convention, unmarshaler, at...)
+		return Set(rval.Elem(), node, convention, sep, unmarshaler, at...)
 	case reflect.Map:
 		if !velem.Type().AssignableTo(typ.Key()) {
 			return wrapErr(fmt.Errorf("cannot index %v with %T %q", typ, elem, elem))
@@ -431,7 +522,7 @@ func Set(val reflect.Value, node syntax.Value, convention encoding.NamingConvent
 		if vval.IsValid() {
 			newvval.Set(vval)
 		}
-		err := Set(newvval, node, convention, unmarshaler, at[1:]...)
+		err := Set(newvval, node, convention, sep, unmarshaler, at[1:]...)
 		if err != nil {
 			return wrapErr(err)
 		}
@@ -454,13 +545,13 @@ func Set(val reflect.Value, node syntax.Value, convention encoding.NamingConvent
 			for i := 0; i < rval.Len(); i++ {
 				nval.Index(i).Set(rval.Index(i))
 			}
-			if err := Set(nval.Index(idx), node, convention, unmarshaler, at[1:]...); err != nil {
+			if err := Set(nval.Index(idx), node, convention, sep, unmarshaler, at[1:]...); err != nil {
 				return wrapErr(err)
 			}
 			val.Set(nval)
 			return nil
 		}
-		if err := Set(rval.Index(idx), node, convention, unmarshaler, at[1:]...); err != nil {
+		if err := Set(rval.Index(idx), node, convention, sep, unmarshaler, at[1:]...); err != nil {
 			return wrapErr(err)
 		}
 		return nil
@@ -471,7 +562,7 @@ func Set(val reflect.Value, node syntax.Value, convention encoding.NamingConvent
 		}
 
 		if field, ok := LookupField(rval, convention, unmarshaler, fname); ok {
-			return wrapErr(Set(field.Value, node, field.Options.Naming, unmarshaler, at[1:]...))
+			return wrapErr(Set(field.Value, node, field.Options.Naming, fieldSep(sep, field.Options), unmarshaler, at[1:]...))
 		}
 		return nil
 	default:
@@ -49,6 +49,10 @@ func makeLayoutKey(typ reflect.Type, convention encoding.NamingConvention, unmar
 		// it a stable identity key with no allocation.
 		conventionPtr = (*[2]unsafe.Pointer)(unsafe.Pointer(&convention))[1]
 	}
+	if wrapper, ok := unmarshaler.(StringScalars); ok {
+		// Fields are laid out the same way as with the wrapped unmarshaler.
+		unmarshaler = wrapper.Unmarshaler
+	}
 	var unmarshalerType reflect.Type
 	if unmarshaler != nil {
 		unmarshalerType = reflect.TypeOf(unmarshaler)
@@ -0,0 +1,54 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package xdg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func userDir(env, what string, fallback ...string) (string, error) {
+	dir := os.Getenv(env)
+	if dir == "" {
+		home := os.Getenv("HOME")
+		if home == "" {
+			return "", fmt.Errorf("could not find the user %s: no $HOME set", what)
+		}
+		dir = filepath.Join(append([]string{home}, fallback...)...)
+	}
+	return dir, nil
+}
+
+func DataHome() (string, error) {
+	return userDir("XDG_DATA_HOME", "data home", ".local", "share")
+}
+
+func StateHome() (string, error) {
+	return userDir("XDG_STATE_HOME", "state home", ".local", "state")
+}
+
+func CacheHome() (string, error) {
+	return userDir("XDG_CACHE_HOME", "cache home", ".cache")
+}
+
+func RuntimeDir() (string, error) {
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		return "", errors.New("could not find the user runtime directory: no $XDG_RUNTIME_DIR set")
+	}
+	return runtimeDir, nil
+}
+
+func DataDirs() []string {
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+	return strings.Split(dataDirs, ":")
+}
@@ -0,0 +1,172 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"snai.pe/boa/internal/encutil"
+	"snai.pe/boa/syntax"
+)
+
+// NewKeyPerFileFS returns an io/fs.FS containing a single configuration file
+// under the specified name, whose keys are the files of the dir directory.
+// The name of each file is the key path of a value, with sep separating the
+// nested keys (if sep is empty, keys are not nested), and the contents of the
+// file are the value itself. A single trailing line break is stripped from
+// the contents.
+//
+// This maps directories that hold one file per key, like Kubernetes ConfigMap
+// and Secret volumes, onto configuration values. For instance, with a
+// directory holding the following files:
+//
+//	server.port      (8080)
+//	database.secret  (hunter2)
+//
+// NewKeyPerFileFS("program.toml", os.DirFS(dir), ".") returns an FS object
+// such that opening program.toml opens a configuration file equivalent to:
+//
+//	[server]
+//	port = 8080
+//
+//	[database]
+//	secret = "hunter2"
+//
+// The extension of the name determines the configuration language whose
+// naming convention and struct tags are used to match keys. Values are
+// converted to the type of the fields that they are loaded into, like
+// environment variables are. Hidden files and directories are ignored.
+//
+// Like NewSingleFileFS, this is meant to be passed to Open and OpenMultiple,
+// usually as the most important path.
+func NewKeyPerFileFS(name string, dir fs.FS, sep string) fs.FS {
+	return keyPerFileFS{Name: name, Dir: dir, Sep: sep}
+}
+
+// NewCredentialsFS is like NewKeyPerFileFS, but with the directory of the
+// credentials that systemd passes to the service (see systemd.exec(5)), as
+// designated by the $CREDENTIALS_DIRECTORY environment variable.
+//
+// If $CREDENTIALS_DIRECTORY is not set, NewCredentialsFS returns nil.
+func NewCredentialsFS(name, sep string) fs.FS {
+	dir := os.Getenv("CREDENTIALS_DIRECTORY")
+	if dir == "" {
+		return nil
+	}
+	return NewKeyPerFileFS(name, os.DirFS(dir), sep)
+}
+
+type keyPerFileFS struct {
+	Name string
+	Dir  fs.FS
+	Sep  string
+}
+
+func (f keyPerFileFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, fs.ErrInvalid
+	}
+	if name != f.Name {
+		return nil, fs.ErrNotExist
+	}
+	return &keyPerFile{keyPerFileFS: f}, nil
+}
+
+// keyPerFile is the virtual configuration file of a keyPerFileFS. It reads
+// as an empty file, and provides the document made of the key files to the
+// decoders.
+type keyPerFile struct {
+	keyPerFileFS
+}
+
+func (f *keyPerFile) Read([]byte) (int, error) {
+	return 0, io.EOF
+}
+
+func (f *keyPerFile) Close() error {
+	return nil
+}
+
+func (f *keyPerFile) Stat() (fs.FileInfo, error) {
+	return f, nil
+}
+
+// Name implements fs.FileInfo.
+func (f *keyPerFile) Name() string       { return path.Base(f.keyPerFileFS.Name) }
+func (f *keyPerFile) Size() int64        { return 0 }
+func (f *keyPerFile) Mode() fs.FileMode  { return 0444 }
+func (f *keyPerFile) ModTime() time.Time { return time.Time{} }
+func (f *keyPerFile) IsDir() bool        { return false }
+func (f *keyPerFile) Sys() interface{}   { return nil }
+
+func (f *keyPerFile) Document() (*syntax.Document, error) {
+	entries, err := fs.ReadDir(f.Dir, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	root := &syntax.Map{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		data, err := fs.ReadFile(f.Dir, name)
+		if err != nil {
+			return nil, err
+		}
+		value := strings.TrimSuffix(string(data), "\n")
+		value = strings.TrimSuffix(value, "\r")
+
+		keys := []string{name}
+		if f.Sep != "" {
+			keys = strings.Split(name, f.Sep)
+		}
+
+		m := root
+		for i, key := range keys {
+			var entry *syntax.MapEntry
+			for _, e := range m.Entries {
+				if e.Key.(*syntax.String).Value == key {
+					entry = e
+					break
+				}
+			}
+			last := i == len(keys)-1
+
+			switch {
+			case entry == nil && last:
+				m.Entries = append(m.Entries, &syntax.MapEntry{
+					Key:   &syntax.String{Value: key},
+					Value: &syntax.String{Value: value},
+				})
+			case entry == nil:
+				sub := &syntax.Map{}
+				m.Entries = append(m.Entries, &syntax.MapEntry{
+					Key:   &syntax.String{Value: key},
+					Value: sub,
+				})
+				m = sub
+			default:
+				sub, ok := entry.Value.(*syntax.Map)
+				if last || !ok {
+					return nil, fmt.Errorf("%s: key %q conflicts with another file", name, strings.Join(keys[:i+1], f.Sep))
+				}
+				m = sub
+			}
+		}
+	}
+	return &syntax.Document{Root: root}, nil
+}
+
+var _ encutil.DocumentFile = (*keyPerFile)(nil)
@@ -0,0 +1,86 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestKeyPerFileFS(t *testing.T) {
+	type Config struct {
+		Name   string
+		Server struct {
+			Host string
+			Port int
+			TLS  *bool
+		}
+		Database struct {
+			User     string
+			Password string `toml:"secret"`
+		}
+		Labels map[string]string
+	}
+
+	base := fstest.MapFS{
+		"app.toml": {Data: []byte("name = \"base\"\n[server]\nhost = \"localhost\"\nport = 80\n")},
+	}
+	secrets := fstest.MapFS{
+		"server.port":        {Data: []byte("8080\n")},
+		"server.tls":         {Data: []byte("true")},
+		"database.user":      {Data: []byte("admin")},
+		"database.secret":    {Data: []byte("hunter2\r\n")},
+		"labels.team":        {Data: []byte("infra")},
+		"..data/server.port": {Data: []byte("1")},
+		".hidden":            {Data: []byte("ignored")},
+	}
+
+	var cfg Config
+	err := NewDecoder(Open("app", base, NewKeyPerFileFS("app.toml", secrets, "."))).
+		Option(AutomaticEnv("APP"), Environ([]string{"APP_NAME=env"})).
+		Decode(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expected Config
+	expected.Name = "env"
+	expected.Server.Host = "localhost"
+	expected.Server.Port = 8080
+	expected.Server.TLS = new(bool)
+	*expected.Server.TLS = true
+	expected.Database.User = "admin"
+	expected.Database.Password = "hunter2"
+	expected.Labels = map[string]string{"team": "infra"}
+
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, cfg)
+	}
+
+	conflicting := fstest.MapFS{
+		"server":      {Data: []byte("value")},
+		"server.port": {Data: []byte("8080")},
+	}
+	err = NewDecoder(Open("app", NewKeyPerFileFS("app.toml", conflicting, "."))).Decode(&cfg)
+	if err == nil {
+		t.Fatal("expected conflict error")
+	}
+
+	invalid := fstest.MapFS{
+		"server.port": {Data: []byte("http")},
+	}
+	err = NewDecoder(Open("app", NewKeyPerFileFS("app.toml", invalid, "."))).Decode(&cfg)
+	if err == nil {
+		t.Fatal("expected error when loading a non-numeric port")
+	}
+
+	// Nil paths, like the one of NewCredentialsFS without credentials, are skipped.
+	t.Setenv("CREDENTIALS_DIRECTORY", "")
+	if err := NewDecoder(Open("app", base, NewCredentialsFS("app.toml", "."))).Decode(&cfg); err != nil {
+		t.Fatal(err)
+	}
+}
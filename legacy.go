@@ -0,0 +1,241 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"snai.pe/boa/encoding"
+)
+
+// LegacyPath is a location where older versions of a program kept their
+// configuration, before they followed the conventions of ConfigPaths.
+type LegacyPath struct {
+	// Path is the filesystem path of the legacy file, such as ~/.myapprc.
+	// A leading ~ designates the home directory of the user.
+	Path string
+
+	// Ext is the extension of the configuration language of the file, such
+	// as ".toml". If empty, the extension of Path is used.
+	Ext string
+}
+
+// Legacy describes the legacy locations of a configuration, which are
+// consulted when no configuration file is found in the search paths.
+type Legacy struct {
+	// Paths are the legacy locations, in order of preference. Only the first
+	// one that exists is loaded.
+	Paths []LegacyPath
+
+	// Warn, if non-nil, is called with a message for the user when a legacy
+	// file is loaded or migrated, or when its migration fails. If nil,
+	// nothing is reported, not even failed migrations: set it, for instance
+	// to log.Print, to tell users that their configuration moved.
+	Warn func(msg string)
+
+	// Migrate, if true, copies the legacy file into the configuration home
+	// under the name of the configuration, where it is found from then on.
+	// The configuration home is ConfigHome(), unless it was overridden with
+	// a WritableFS by SetConfigHomeFS or Loader.ConfigHome. The legacy
+	// file is left in place. If MigrateExt is set and differs from the
+	// extension of the legacy file, the configuration is converted to the
+	// language of MigrateExt.
+	Migrate    bool
+	MigrateExt string
+}
+
+var legacyConfigs = map[string]Legacy{}
+
+// SetLegacy sets the legacy locations of the configuration with the
+// specified name (without extension), which Open and OpenMultiple consult
+// when they are called without search paths, and when no file matches the
+// name in ConfigPaths. Legacy files are loaded silently unless Warn is set.
+// For instance:
+//
+//	boa.SetLegacy("myapp", boa.Legacy{
+//		Paths: []boa.LegacyPath{
+//			{Path: "~/.myapprc", Ext: ".toml"},
+//			{Path: "~/.myapp/config.toml"},
+//		},
+//		Warn:    func(msg string) { log.Print(msg) },
+//		Migrate: true,
+//	})
+func SetLegacy(name string, legacy Legacy) {
+	legacyConfigs[name] = legacy
+}
+
+// legacyFallback holds what a FileSet needs to load and migrate legacy
+// files.
+type legacyFallback struct {
+	configs  map[string]Legacy
+	decoders map[string]func(io.Reader) encoding.Decoder
+	encoders map[string]func(io.Writer) encoding.Encoder
+
+	// home returns the configuration home that legacy files are migrated
+	// into.
+	home func() (WritableFS, error)
+}
+
+// open opens the first legacy file of the configuration with the specified
+// name whose extension is one of exts, migrating it if requested. It returns
+// a nil file if there is none.
+func (fallback *legacyFallback) open(name string, exts []string) (fs.File, string, error) {
+	stem := strings.TrimSuffix(name, filepath.Ext(name))
+	legacy, ok := fallback.configs[stem]
+	if !ok {
+		return nil, "", nil
+	}
+	warn := legacy.Warn
+	if warn == nil {
+		warn = func(string) {}
+	}
+
+	for _, lp := range legacy.Paths {
+		path, err := expandHome(lp.Path)
+		if err != nil {
+			continue
+		}
+		ext := lp.Ext
+		if ext == "" {
+			ext = filepath.Ext(path)
+		}
+		if realext := filepath.Ext(name); realext != "" && realext != ext {
+			continue
+		}
+		if !containsString(exts, ext) {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			continue
+		case err != nil:
+			return nil, "", err
+		}
+
+		if legacy.Migrate {
+			home, migrated, err := fallback.migrate(stem, data, ext, legacy.MigrateExt)
+			if err == nil {
+				used := fmt.Sprintf("%v/%v", fsPath(home), migrated)
+				warn(fmt.Sprintf("migrated legacy configuration file %s to %s", path, used))
+				f, err := home.Open(migrated)
+				return f, used, err
+			}
+			warn(fmt.Sprintf("using legacy configuration file %s: migration failed: %v", path, err))
+		} else {
+			warn(fmt.Sprintf("using legacy configuration file %s", path))
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, "", err
+		}
+		return renamedFile{File: f, name: filepath.Base(stem) + ext}, path, nil
+	}
+	return nil, "", nil
+}
+
+// migrate writes the legacy configuration data, in the language of ext,
+// into the configuration home in the language of newext, and returns the
+// configuration home and the name of the new file in it.
+func (fallback *legacyFallback) migrate(stem string, data []byte, ext, newext string) (WritableFS, string, error) {
+	if newext == "" {
+		newext = ext
+	}
+	home, err := fallback.home()
+	if err != nil {
+		return nil, "", err
+	}
+	name := filepath.ToSlash(stem) + newext
+	if _, err := fs.Stat(home, name); err == nil {
+		return nil, "", fmt.Errorf("%s/%s already exists", fsPath(home), name)
+	}
+
+	if newext != ext {
+		newDecoder, ok := fallback.decoders[ext]
+		if !ok {
+			return nil, "", fmt.Errorf("no known decoder for file extension %q", ext)
+		}
+		newEncoder, ok := fallback.encoders[newext]
+		if !ok {
+			return nil, "", fmt.Errorf("no known encoder for file extension %q", newext)
+		}
+		var v interface{}
+		if err := newDecoder(bytes.NewReader(data)).Decode(&v); err != nil {
+			return nil, "", err
+		}
+		var out bytes.Buffer
+		if err := newEncoder(&out).Encode(v); err != nil {
+			return nil, "", err
+		}
+		data = out.Bytes()
+	}
+
+	err = writeFile(home, name, 0, false, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return home, name, nil
+}
+
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// renamedFile is a file whose name is reported differently, so that the
+// configuration language of legacy files without extension is known.
+type renamedFile struct {
+	fs.File
+	name string
+}
+
+func (f renamedFile) Name() string {
+	return f.name
+}
+
+func (f renamedFile) Stat() (fs.FileInfo, error) {
+	info, err := f.File.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return renamedInfo{FileInfo: info, name: f.name}, nil
+}
+
+type renamedInfo struct {
+	fs.FileInfo
+	name string
+}
+
+func (info renamedInfo) Name() string {
+	return info.name
+}
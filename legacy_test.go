@@ -0,0 +1,157 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLegacy(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("XDG configuration paths require a unix system")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+	t.Setenv("XDG_CONFIG_DIRS", filepath.Join(home, "sys"))
+
+	if err := os.WriteFile(filepath.Join(home, ".myapprc"), []byte("name = \"legacy\"\nport = 8080\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	type Config struct {
+		Name string
+		Port int
+	}
+
+	var warnings []string
+	legacy := Legacy{
+		Paths: []LegacyPath{
+			{Path: "~/.myapp/config.json"},
+			{Path: "~/.myapprc", Ext: ".toml"},
+		},
+		Warn: func(msg string) { warnings = append(warnings, msg) },
+	}
+
+	// Files in the search paths take precedence over legacy files.
+	var config Config
+	paths := fstest.MapFS{"myapp.toml": &fstest.MapFile{Data: []byte("name = \"current\"\n")}}
+	err := NewLoader().Paths(paths).Legacy("myapp", legacy).Files("myapp").Load(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Name != "current" || len(warnings) != 0 {
+		t.Fatalf("unexpected config %+v and warnings %q", config, warnings)
+	}
+
+	config = Config{}
+	err = NewLoader().Paths().Legacy("myapp", legacy).Files("myapp").Load(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Name != "legacy" || config.Port != 8080 {
+		t.Fatalf("unexpected config %+v", config)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], ".myapprc") {
+		t.Fatalf("unexpected warnings %q", warnings)
+	}
+
+	// Migrating converts the legacy file into the configuration home.
+	legacy.Migrate = true
+	legacy.MigrateExt = ".json5"
+	warnings = nil
+	config = Config{}
+	err = NewLoader().Paths().Legacy("myapp", legacy).Files("myapp").Load(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Name != "legacy" || config.Port != 8080 {
+		t.Fatalf("unexpected config %+v", config)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "migrated") {
+		t.Fatalf("unexpected warnings %q", warnings)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".myapprc")); err != nil {
+		t.Fatalf("legacy file was not kept: %v", err)
+	}
+
+	// The migrated file is found in the configuration home from then on.
+	warnings = nil
+	config = Config{}
+	err = NewLoader().Legacy("myapp", legacy).Files("myapp").Load(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Name != "legacy" || config.Port != 8080 || len(warnings) != 0 {
+		t.Fatalf("unexpected config %+v and warnings %q", config, warnings)
+	}
+}
+
+func TestLegacyMigrateConfigHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+
+	if err := os.WriteFile(filepath.Join(home, ".myapprc"), []byte("name = \"legacy\"\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	legacy := Legacy{
+		Paths:   []LegacyPath{{Path: "~/.myapprc", Ext: ".toml"}},
+		Migrate: true,
+	}
+
+	// Without Warn, legacy files are loaded silently.
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	type Config struct {
+		Name string
+	}
+
+	// The legacy file is migrated into the configuration home of the
+	// loader, rather than into ConfigHome().
+	mem := NewMemFS(nil)
+	var config Config
+	err := NewLoader().ConfigHome(mem).Legacy("myapp", legacy).Files("myapp").Load(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Name != "legacy" {
+		t.Fatalf("unexpected config %+v", config)
+	}
+	if data, err := fs.ReadFile(mem, "myapp.toml"); err != nil || string(data) != "name = \"legacy\"\n" {
+		t.Fatalf("legacy file was not migrated into the configuration home: %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(home, "config", "myapp.toml")); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("legacy file was migrated outside of the configuration home: %v", err)
+	}
+
+	// Configuration homes that are not writable are not migrated into.
+	config = Config{}
+	err = NewLoader().ConfigHome(fstest.MapFS{}).Legacy("myapp", legacy).Files("myapp").Load(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Name != "legacy" {
+		t.Fatalf("unexpected config %+v", config)
+	}
+	if _, err := os.Stat(filepath.Join(home, "config", "myapp.toml")); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("legacy file was migrated outside of the configuration home: %v", err)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("unexpected warnings without Warn: %q", logs.String())
+	}
+}
@@ -0,0 +1,417 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"snai.pe/boa/encoding"
+	"snai.pe/boa/syntax"
+)
+
+// A Loader loads configuration values from a set of layers, without
+// depending on the package-level state of SetOptions, SetDefaults,
+// SetConfigHomeFS, Decoders, and Encoders. The layers are applied in the
+// following order, each overriding the previous ones:
+//
+//  1. the configuration files found in the search paths (see Paths and
+//     Project);
+//  2. the environment variables (see Env);
+//  3. the command-line flags (see Flags);
+//  4. the overrides (see Set and Overrides).
+//
+// A Loader is configured with its builder methods, which return the loader
+// itself so that calls can be chained. It must not be modified while it is
+// used, but Load may be called concurrently.
+type Loader struct {
+	decoders       map[string]func(io.Reader) encoding.Decoder
+	encoders       map[string]func(io.Writer) encoding.Encoder
+	decoderOptions []interface{}
+	encoderOptions []interface{}
+
+	names    []string
+	paths    []fs.FS
+	defaults fs.FS
+	home     fs.FS
+	flags    []*flag.FlagSet
+
+	project        string
+	projectMarkers []string
+
+	legacy map[string]Legacy
+
+	configFile     string
+	configFileMode ConfigFileMode
+	hasConfigFile  bool
+
+	// stdin caches the standard input once it is read as the configuration
+	// file, so that every Load sees the same contents.
+	stdinOnce sync.Once
+	stdin     []byte
+	stdinErr  error
+
+	overrides []*syntax.Document
+	err       error
+}
+
+// NewLoader returns a new Loader that knows the built-in configuration
+// languages, and has no options set.
+//
+// Unless Paths is called, the search paths are the same as the ones that
+// ConfigPaths returns, minus the effects of SetDefaults and SetConfigHomeFS.
+func NewLoader() *Loader {
+	return &Loader{
+		decoders: builtinDecoders(),
+		encoders: builtinEncoders(),
+	}
+}
+
+// defaultLoader returns the loader of the package-level functions, which
+// uses the package-level state.
+func defaultLoader() *Loader {
+	return &Loader{
+		decoders:       Decoders,
+		encoders:       Encoders,
+		decoderOptions: append([]interface{}(nil), defaultDecoderOptions...),
+		encoderOptions: append([]interface{}(nil), defaultEncoderOptions...),
+		defaults:       defaultPath,
+		home:           configHomeFS,
+		legacy:         legacyConfigs,
+	}
+}
+
+// stateLoader returns the loader of the state files in dir, which has the
+// options of the package-level functions, but neither their configuration
+// paths nor their legacy locations.
+func stateLoader(dir string) *Loader {
+	return &Loader{
+		decoders:       Decoders,
+		encoders:       Encoders,
+		decoderOptions: append([]interface{}(nil), defaultDecoderOptions...),
+		encoderOptions: append([]interface{}(nil), defaultEncoderOptions...),
+		paths:          []fs.FS{os.DirFS(dir)},
+	}
+}
+
+// Decoder associates the specified file extension with a decoder, in the
+// same way as the Decoders map does. A nil newDecoder removes the extension.
+func (l *Loader) Decoder(ext string, newDecoder func(io.Reader) encoding.Decoder) *Loader {
+	if newDecoder == nil {
+		delete(l.decoders, ext)
+	} else {
+		l.decoders[ext] = newDecoder
+	}
+	return l
+}
+
+// Encoder associates the specified file extension with an encoder, in the
+// same way as the Encoders map does. A nil newEncoder removes the extension.
+func (l *Loader) Encoder(ext string, newEncoder func(io.Writer) encoding.Encoder) *Loader {
+	if newEncoder == nil {
+		delete(l.encoders, ext)
+	} else {
+		l.encoders[ext] = newEncoder
+	}
+	return l
+}
+
+// Option adds common, encoder-specific, and decoder-specific options, in the
+// same way as SetOptions does.
+func (l *Loader) Option(opts ...interface{}) *Loader {
+	for _, opt := range opts {
+		switch opt.(type) {
+		case CommonOption:
+			l.decoderOptions = append(l.decoderOptions, opt)
+			l.encoderOptions = append(l.encoderOptions, opt)
+		case EncoderOption:
+			l.encoderOptions = append(l.encoderOptions, opt)
+		case DecoderOption:
+			l.decoderOptions = append(l.decoderOptions, opt)
+		default:
+			panic(fmt.Sprintf("%T is not an option, encoder option or decoder option.", opt))
+		}
+	}
+	return l
+}
+
+// Files sets the names of the configuration files to load, in the same way
+// as OpenMultiple does. Without names, no configuration file is loaded.
+func (l *Loader) Files(names ...string) *Loader {
+	checkNames(names...)
+	l.names = names
+	return l
+}
+
+// Paths sets the search paths of the configuration files, in order of least
+// important to most important. It replaces the default search paths, as well
+// as the ones set by Defaults and ConfigHome, but not the project paths.
+func (l *Loader) Paths(paths ...fs.FS) *Loader {
+	l.paths = append([]fs.FS{}, paths...)
+	return l
+}
+
+// Defaults sets the FS object containing configuration file defaults, which
+// is the least important of the default search paths.
+func (l *Loader) Defaults(defaults fs.FS) *Loader {
+	l.defaults = defaults
+	return l
+}
+
+// ConfigHome overrides the user configuration home, which is the most
+// important of the default search paths.
+func (l *Loader) ConfigHome(home fs.FS) *Loader {
+	l.home = home
+	return l
+}
+
+// Project adds the project-local configuration paths of start to the search
+// paths, as the most important ones (see ProjectPaths). If start is empty,
+// the working directory is used.
+func (l *Loader) Project(start string, markers ...string) *Loader {
+	// Resolve the start directory now, in case the working directory changes.
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		if l.err == nil {
+			l.err = err
+		}
+		return l
+	}
+	l.project = dir
+	l.projectMarkers = markers
+	return l
+}
+
+// ConfigFile overrides the search of the first configuration file name with
+// the file at path, such as the value of a -config flag (see ConfigFileFlag),
+// or if path is empty, with the file in the environment variable named after
+// it (see ConfigFileEnv). mode determines whether the file replaces or
+// augments the search paths, as described in ConfigFilePaths.
+func (l *Loader) ConfigFile(path string, mode ConfigFileMode) *Loader {
+	l.configFile = path
+	l.configFileMode = mode
+	l.hasConfigFile = true
+	if path == "-" {
+		if _, err := l.readStdin(); err != nil && l.err == nil {
+			l.err = err
+		}
+	}
+	return l
+}
+
+// readStdin returns the contents of the standard input, which is only read
+// the first time.
+func (l *Loader) readStdin() ([]byte, error) {
+	l.stdinOnce.Do(func() {
+		l.stdin, l.stdinErr = io.ReadAll(os.Stdin)
+	})
+	return l.stdin, l.stdinErr
+}
+
+// Legacy sets the legacy locations of the configuration with the specified
+// name, in the same way as SetLegacy does.
+func (l *Loader) Legacy(name string, legacy Legacy) *Loader {
+	if l.legacy == nil {
+		l.legacy = make(map[string]Legacy)
+	}
+	l.legacy[name] = legacy
+	return l
+}
+
+// Env enables the population of configuration values from the environment
+// variables with the specified prefix, in the same way as the AutomaticEnv
+// option does. The variables are looked up with os.LookupEnv, unless the
+// Environ or EnvironFunc options are set.
+func (l *Loader) Env(prefix string) *Loader {
+	return l.Option(AutomaticEnv(prefix))
+}
+
+// Flags sets the values of the flags of set that were set on the command
+// line, and that are named after a key path (as described in ParseOverrides),
+// such as `-server.port=8080`. The flags must be parsed before Load is
+// called. Flags that do not name a configuration value are ignored.
+func (l *Loader) Flags(set *flag.FlagSet) *Loader {
+	l.flags = append(l.flags, set)
+	return l
+}
+
+// Set sets the values of key path assignments, such as the arguments of
+// repeated `--set` command-line flags, as described in ParseOverrides. The
+// values are strings converted to the type of the fields they are set on.
+//
+// Errors are reported by Load.
+func (l *Loader) Set(args ...string) *Loader {
+	doc, err := ParseOverrides(args, nil)
+	if err != nil {
+		if l.err == nil {
+			l.err = err
+		}
+		return l
+	}
+	return l.Overrides(doc)
+}
+
+// Overrides applies the values of doc after all of the other layers, in the
+// same way as the Overrides option does.
+func (l *Loader) Overrides(doc *syntax.Document) *Loader {
+	l.overrides = append(l.overrides, doc)
+	return l
+}
+
+// SearchPaths returns the search paths of the configuration files, in order
+// of least important to most important.
+//
+// If the project-local paths cannot be determined, they are omitted, and the
+// error is returned by Load.
+func (l *Loader) SearchPaths() []fs.FS {
+	paths, err := l.searchPaths()
+	if err != nil && l.err == nil {
+		l.err = err
+	}
+	return paths
+}
+
+// searchPaths implements SearchPaths, and returns the error of the
+// project-local paths, if any, along with the other paths.
+func (l *Loader) searchPaths() ([]fs.FS, error) {
+	paths := l.configPaths()
+	if l.project == "" {
+		return paths, nil
+	}
+	project, err := ProjectPaths(l.project, l.projectMarkers...)
+	if err != nil {
+		return paths, err
+	}
+	return append(paths[:len(paths):len(paths)], project...), nil
+}
+
+func (l *Loader) configPaths() []fs.FS {
+	if l.paths != nil {
+		return l.paths
+	}
+	dirs := configDirs()
+	paths := make([]fs.FS, 0, len(dirs)+2)
+	if l.defaults != nil {
+		paths = append(paths, l.defaults)
+	}
+	for _, dir := range dirs {
+		paths = append(paths, os.DirFS(dir))
+	}
+	home := l.home
+	if home == nil {
+		if path, err := configHome(); err == nil {
+			home = NewDirFS(path)
+		}
+	}
+	if home != nil {
+		paths = append(paths, home)
+	}
+	return paths
+}
+
+// homeWritableFS returns the configuration home of the loader, into which
+// legacy files are migrated.
+func (l *Loader) homeWritableFS() (WritableFS, error) {
+	switch home := l.home.(type) {
+	case nil:
+		path, err := configHome()
+		if err != nil {
+			return nil, err
+		}
+		return NewDirFS(path), nil
+	case WritableFS:
+		return home, nil
+	default:
+		return nil, fmt.Errorf("configuration home %s is not writable", fsPath(home))
+	}
+}
+
+// NewDecoder returns a new Decoder that reads from in, with the configuration
+// languages and the options of the loader.
+func (l *Loader) NewDecoder(in io.Reader) *Decoder {
+	return &Decoder{
+		in:       in,
+		opts:     append([]interface{}(nil), l.decoderOptions...),
+		decoders: l.decoders,
+	}
+}
+
+// NewEncoder returns a new Encoder that writes into out, with the
+// configuration languages and the options of the loader.
+func (l *Loader) NewEncoder(out encoding.StatableWriter) *Encoder {
+	return &Encoder{
+		out:      out,
+		opts:     append([]interface{}(nil), l.encoderOptions...),
+		encoders: l.encoders,
+	}
+}
+
+// Load loads the configuration layers into the value pointed to by v.
+func (l *Loader) Load(v interface{}) error {
+	if l.err != nil {
+		return l.err
+	}
+
+	var overrides []*syntax.Document
+	for _, set := range l.flags {
+		var args []string
+		set.Visit(func(f *flag.Flag) {
+			args = append(args, f.Name+"="+f.Value.String())
+		})
+		doc, err := ParseOverrides(args, nil)
+		if err != nil {
+			return fmt.Errorf("flag %w", err)
+		}
+		overrides = append(overrides, doc)
+	}
+	overrides = append(overrides, l.overrides...)
+
+	paths, err := l.searchPaths()
+	if err != nil {
+		return err
+	}
+	f := OpenMultiple(l.names, paths...)
+	if l.hasConfigFile && len(l.names) > 0 {
+		// Only the search of the first name is overridden.
+		paths, err := configFilePaths(l.names[0], l.configFile, l.configFileMode, l.decoders, l.readStdin, f.fs)
+		if err != nil {
+			return err
+		}
+		f.namePaths = map[string][]fs.FS{l.names[0]: paths}
+	}
+	if len(l.legacy) > 0 {
+		f.legacy = &legacyFallback{configs: l.legacy, decoders: l.decoders, encoders: l.encoders, home: l.homeWritableFS}
+	}
+	defer f.Close()
+
+	dec := l.NewDecoder(f)
+	for _, doc := range overrides {
+		dec.Option(Overrides(doc))
+	}
+	return dec.Decode(v)
+}
+
+// fileEncoder returns the encoder of the configuration language of the file
+// at path, and the encoder options of the loader.
+func (l *Loader) fileEncoder(path string) (func(io.Writer) encoding.Encoder, encoding.EncoderOptions, error) {
+	var opts encoding.EncoderOptions
+	ext := filepath.Ext(path)
+	newEncoder, ok := l.encoders[ext]
+	if !ok {
+		return nil, opts, fmt.Errorf("no known encoder for file extension %q", ext)
+	}
+	for _, opt := range l.encoderOptions {
+		if opt, ok := opt.(EncoderOption); ok {
+			opt(&opts)
+		}
+	}
+	return newEncoder, opts, nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"snai.pe/boa/encoding/dotenv"
+	"snai.pe/boa/encoding/toml"
+)
+
+func TestLoader(t *testing.T) {
+	type Config struct {
+		Name   string
+		Host   string
+		Port   int
+		Debug  bool
+		Labels map[string]string
+	}
+
+	defaults := fstest.MapFS{
+		"app.toml": {Data: []byte("name = \"defaults\"\nhost = \"localhost\"\nport = 80\n")},
+	}
+	home := fstest.MapFS{
+		"app.conf": {Data: []byte("name = \"home\"\n")},
+	}
+
+	flags := flag.NewFlagSet("app", flag.ContinueOnError)
+	flags.Int("port", 0, "")
+	flags.Bool("debug", false, "")
+	flags.Bool("verbose", false, "")
+	if err := flags.Parse([]string{"-port=8080", "-verbose"}); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewLoader().
+		Decoder(".conf", toml.NewDecoder).
+		Defaults(defaults).
+		ConfigHome(home).
+		Files("app").
+		Env("APP").
+		Option(Environ([]string{"APP_HOST=example.com", "APP_PORT=443", "APP_LABELS_TEAM=infra"})).
+		Flags(flags).
+		Set("labels.team=platform")
+
+	// Don't load the real system configuration directories.
+	paths := loader.SearchPaths()
+	if len(paths) < 2 || !reflect.DeepEqual(paths[0], defaults) || !reflect.DeepEqual(paths[len(paths)-1], home) {
+		t.Fatalf("expected search paths to start with defaults and end with home, got %v", paths)
+	}
+	loader.Paths(defaults, home)
+
+	var config Config
+	if err := loader.Load(&config); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Config{
+		Name:   "home",
+		Host:   "example.com",
+		Port:   8080,
+		Labels: map[string]string{"team": "platform"},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, config)
+	}
+
+	// The package-level state does not affect loaders.
+	SetOptions(AutomaticEnv("APP"), Environ([]string{"APP_DEBUG=true"}))
+	defer func() { defaultDecoderOptions = nil; defaultEncoderOptions = nil }()
+
+	config = Config{}
+	if err := NewLoader().Paths(defaults).Files("app").Load(&config); err != nil {
+		t.Fatal(err)
+	}
+	expected = Config{Name: "defaults", Host: "localhost", Port: 80}
+	if !reflect.DeepEqual(config, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, config)
+	}
+
+	// Without a decoder for .conf, the file in home is skipped.
+	config = Config{}
+	if err := NewLoader().Paths(defaults, home).Files("app").Load(&config); err != nil {
+		t.Fatal(err)
+	}
+	if config.Name != "defaults" {
+		t.Fatalf("expected defaults, got %q", config.Name)
+	}
+
+	err := NewLoader().Set("port").Load(&config)
+	if err == nil || !strings.HasPrefix(err.Error(), "port: ") {
+		t.Fatalf("expected an error naming the argument, got %v", err)
+	}
+}
+
+func TestLoaderDotenv(t *testing.T) {
+	type Config struct {
+		Name string
+		Port int
+	}
+	defaults := fstest.MapFS{"app.toml": {Data: []byte("name = \"toml\"\n")}}
+	home := fstest.MapFS{"app.env": {Data: []byte("PORT=8080\n")}}
+
+	// dotenv files are not searched for by default.
+	var config Config
+	if err := NewLoader().Paths(defaults, home).Files("app").Load(&config); err != nil {
+		t.Fatal(err)
+	}
+	if expected := (Config{Name: "toml"}); config != expected {
+		t.Fatalf("expected %+v, got %+v", expected, config)
+	}
+
+	config = Config{}
+	if err := NewLoader().Decoder(".env", dotenv.NewDecoder).Paths(defaults, home).Files("app").Load(&config); err != nil {
+		t.Fatal(err)
+	}
+	if expected := (Config{Name: "toml", Port: 8080}); config != expected {
+		t.Fatalf("expected %+v, got %+v", expected, config)
+	}
+}
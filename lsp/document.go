@@ -0,0 +1,555 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package lsp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/constant"
+	"path"
+	"reflect"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"snai.pe/boa"
+	"snai.pe/boa/encoding"
+	"snai.pe/boa/encoding/json5"
+	"snai.pe/boa/encoding/toml"
+	"snai.pe/boa/internal/encutil"
+	"snai.pe/boa/syntax"
+)
+
+// format describes the per-language conventions that the server needs in
+// order to map keys to Go fields and to reformat documents.
+type format struct {
+	naming   encoding.NamingConvention
+	tag      string
+	reformat interface{}
+}
+
+var formats = map[string]format{
+	".toml":  {naming: encoding.SnakeCase, tag: "toml", reformat: toml.Reformat()},
+	".json5": {naming: encoding.CamelCase, tag: "json", reformat: json5.Reformat()},
+	".json":  {naming: encoding.CamelCase, tag: "json", reformat: json5.Reformat()},
+	".yaml":  {naming: encoding.KebabCase, tag: "yaml"},
+	".yml":   {naming: encoding.KebabCase, tag: "yaml"},
+}
+
+type document struct {
+	uri  string
+	ext  string
+	text string
+
+	// ast is the last successfully parsed syntax tree of the document. It
+	// may be stale if the current text does not parse.
+	ast *syntax.Document
+	err error
+}
+
+func newDocument(uri, text string) *document {
+	doc := &document{uri: uri, ext: path.Ext(uri)}
+	doc.update(text)
+	return doc
+}
+
+func (doc *document) update(text string) {
+	doc.text = text
+	ast, err := parse(doc.ext, text)
+	doc.err = err
+	if err == nil {
+		doc.ast = ast
+	}
+}
+
+func parse(ext, text string) (*syntax.Document, error) {
+	newDecoder, ok := boa.Decoders[ext]
+	if !ok {
+		return nil, fmt.Errorf("no known decoder for file extension %q", ext)
+	}
+	var ast *syntax.Document
+	if err := newDecoder(strings.NewReader(text)).Decode(&ast); err != nil {
+		return nil, err
+	}
+	return ast, nil
+}
+
+// lines returns the lines of the document text, without line terminators.
+func (doc *document) lines() []string {
+	return strings.Split(strings.ReplaceAll(doc.text, "\r\n", "\n"), "\n")
+}
+
+// position converts a 1-based syntax cursor (counted in runes) to a 0-based
+// LSP position (counted in UTF-16 code units).
+func (doc *document) position(c syntax.Cursor) Position {
+	if c.Line < 1 {
+		return Position{}
+	}
+	lines := doc.lines()
+	if c.Line > len(lines) {
+		return Position{Line: c.Line - 1}
+	}
+	line := lines[c.Line-1]
+	units := 0
+	for i, r := range []rune(line) {
+		if i >= c.Column-1 {
+			break
+		}
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return Position{Line: c.Line - 1, Character: units}
+}
+
+// cursor converts a 0-based LSP position to a 1-based syntax cursor.
+func (doc *document) cursor(p Position) syntax.Cursor {
+	lines := doc.lines()
+	if p.Line >= len(lines) {
+		return syntax.Cursor{Line: p.Line + 1, Column: p.Character + 1}
+	}
+	col, units := 0, 0
+	for _, r := range lines[p.Line] {
+		if units >= p.Character {
+			break
+		}
+		units += len(utf16.Encode([]rune{r}))
+		col++
+	}
+	return syntax.Cursor{Line: p.Line + 1, Column: col + 1}
+}
+
+// lineEnd returns the position of the end of the line containing c.
+func (doc *document) lineEnd(c syntax.Cursor) Position {
+	lines := doc.lines()
+	if c.Line < 1 || c.Line > len(lines) {
+		return doc.position(c)
+	}
+	line := strings.TrimRight(lines[c.Line-1], " \t,")
+	return doc.position(syntax.Cursor{Line: c.Line, Column: utf8.RuneCountInString(line) + 1})
+}
+
+// end returns the position of the end of the document.
+func (doc *document) end() Position {
+	lines := doc.lines()
+	last := lines[len(lines)-1]
+	return Position{Line: len(lines) - 1, Character: len(utf16.Encode([]rune(last)))}
+}
+
+// errorRange computes the range that an error should be reported at.
+func (doc *document) errorRange(err error) Range {
+	var (
+		tokErr  syntax.TokenTypeError
+		synErr  *syntax.Error
+		loadErr *encoding.LoadError
+	)
+	switch {
+	case errors.As(err, &tokErr) && tokErr.Token.Start.Line > 0:
+		start, end := tokErr.Token.Start, tokErr.Token.End
+		end.Column++
+		return Range{Start: doc.position(start), End: doc.position(end)}
+	case errors.As(err, &synErr):
+		return Range{Start: doc.position(synErr.Cursor), End: doc.lineEnd(synErr.Cursor)}
+	case errors.As(err, &loadErr):
+		return Range{Start: doc.position(loadErr.Cursor), End: doc.lineEnd(loadErr.Cursor)}
+	}
+	return Range{}
+}
+
+func isTrivia(tok syntax.Token) bool {
+	switch tok.Type {
+	case syntax.TokenWhitespace, syntax.TokenNewline, syntax.TokenComment, syntax.TokenInlineComment, syntax.TokenEOF:
+		return true
+	}
+	return strings.Trim(tok.Raw, "[]{}.:=,-? \t") == ""
+}
+
+// keyComponents returns the path components designated by a map key, along
+// with the tokens that spell each component.
+func keyComponents(key syntax.Value) ([]interface{}, []syntax.Token) {
+	var toks []syntax.Token
+	for _, tok := range key.Base().Tokens {
+		if !isTrivia(tok) {
+			toks = append(toks, tok)
+		}
+	}
+	switch k := key.(type) {
+	case syntax.KeyPather:
+		comps := k.KeyPathComponents()
+		// Array-of-tables keys have trailing index components that are not
+		// spelled out in the source.
+		if len(toks) > len(comps) {
+			toks = toks[:len(comps)]
+		}
+		return comps, toks
+	case *syntax.String:
+		return []interface{}{k.Value}, toks
+	case *syntax.Number:
+		if v, ok := k.Value.(constant.Value); ok {
+			return []interface{}{v.ExactString()}, toks
+		}
+	case *syntax.Bool:
+		return []interface{}{fmt.Sprint(k.Value)}, toks
+	}
+	return nil, toks
+}
+
+func isHeader(key syntax.Value) bool {
+	for _, tok := range key.Base().Tokens {
+		if tok.Raw == "[" || tok.Raw == "[[" {
+			return true
+		}
+	}
+	return false
+}
+
+func before(a, b syntax.Cursor) bool {
+	return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+}
+
+// keyAt returns the full path of the key component under the cursor, and
+// the token that spells it.
+func keyAt(v syntax.Value, prefix []interface{}, at syntax.Cursor) ([]interface{}, *syntax.Token) {
+	switch n := v.(type) {
+	case *syntax.Map:
+		for _, entry := range n.Entries {
+			comps, toks := keyComponents(entry.Key)
+			full := append(append([]interface{}(nil), prefix...), comps...)
+			for i, tok := range toks {
+				end := tok.End
+				end.Column++
+				if !before(at, tok.Start) && before(at, end) {
+					tok := tok
+					if i < len(comps) {
+						return full[:len(prefix)+i+1], &tok
+					}
+					return full, &tok
+				}
+			}
+			if path, tok := keyAt(entry.Value, full, at); tok != nil {
+				return path, tok
+			}
+		}
+	case *syntax.List:
+		for i, item := range n.Items {
+			if path, tok := keyAt(item, append(append([]interface{}(nil), prefix...), i), at); tok != nil {
+				return path, tok
+			}
+		}
+	}
+	return nil, nil
+}
+
+// start returns the position of the first significant token of a node.
+func start(v syntax.Value) syntax.Cursor {
+	for _, tok := range v.Base().Tokens {
+		if !isTrivia(tok) || tok.Raw == "{" || tok.Raw == "[" || tok.Raw == "-" {
+			return tok.Start
+		}
+	}
+	return v.Base().Position
+}
+
+// end returns the position of the last token of a node, including the
+// tokens of its children.
+func end(v syntax.Value) syntax.Cursor {
+	var last syntax.Cursor
+	update := func(toks []syntax.Token) {
+		for _, tok := range toks {
+			if tok.Type != syntax.TokenEOF && before(last, tok.End) {
+				last = tok.End
+			}
+		}
+	}
+	var walk func(v syntax.Value)
+	walk = func(v syntax.Value) {
+		if v == nil {
+			return
+		}
+		update(v.Base().Tokens)
+		switch n := v.(type) {
+		case *syntax.Map:
+			for _, entry := range n.Entries {
+				walk(entry.Key)
+				walk(entry.Value)
+			}
+		case *syntax.List:
+			for _, item := range n.Items {
+				walk(item)
+			}
+		}
+		update(v.Base().Suffix)
+	}
+	walk(v)
+	return last
+}
+
+// enclosingPath returns the path of the map in which a key typed at the
+// cursor would be inserted.
+func enclosingPath(ast *syntax.Document, ext string, at syntax.Cursor) []interface{} {
+	root, ok := ast.Root.(*syntax.Map)
+	if !ok {
+		return nil
+	}
+	switch ext {
+	case ".toml":
+		// Keys belong to the last table header preceding the cursor.
+		var path []interface{}
+		for _, entry := range root.Entries {
+			if isHeader(entry.Key) && before(start(entry.Key), at) && start(entry.Key).Line < at.Line {
+				path, _ = keyComponents(entry.Key)
+			}
+		}
+		return path
+	case ".yaml", ".yml":
+		return indentedPath(root, nil, at)
+	default:
+		return bracedPath(root, nil, at)
+	}
+}
+
+// indentedPath locates the enclosing map of an indentation-based document:
+// a key belongs to the last preceding entry that is less indented.
+func indentedPath(v syntax.Value, path []interface{}, at syntax.Cursor) []interface{} {
+	switch n := v.(type) {
+	case *syntax.Map:
+		var last *syntax.MapEntry
+		for _, entry := range n.Entries {
+			if start(entry.Key).Line < at.Line {
+				last = entry
+			}
+		}
+		if last == nil || at.Column <= start(last.Key).Column {
+			return path
+		}
+		switch last.Value.(type) {
+		case *syntax.Map, *syntax.List:
+		default:
+			// Indented lines following an inline scalar are continuation
+			// lines, not nested keys.
+			if start(last.Value).Line == start(last.Key).Line {
+				return path
+			}
+		}
+		comps, _ := keyComponents(last.Key)
+		return indentedPath(last.Value, append(path, comps...), at)
+	case *syntax.List:
+		for i := len(n.Items) - 1; i >= 0; i-- {
+			if start(n.Items[i]).Line <= at.Line {
+				return indentedPath(n.Items[i], append(path, i), at)
+			}
+		}
+	}
+	return path
+}
+
+// bracedPath locates the innermost map whose braces surround the cursor.
+func bracedPath(v syntax.Value, path []interface{}, at syntax.Cursor) []interface{} {
+	switch n := v.(type) {
+	case *syntax.Map:
+		for _, entry := range n.Entries {
+			val := entry.Value
+			switch val.(type) {
+			case *syntax.Map, *syntax.List:
+			default:
+				continue
+			}
+			if before(start(val), at) && !before(end(val), at) {
+				comps, _ := keyComponents(entry.Key)
+				return bracedPath(val, append(path, comps...), at)
+			}
+		}
+	case *syntax.List:
+		for i, item := range n.Items {
+			if before(start(item), at) && !before(end(item), at) {
+				return bracedPath(item, append(path, i), at)
+			}
+		}
+	}
+	return path
+}
+
+// lookupMap returns the map node at the specified path, if any.
+func lookupMap(v syntax.Value, path []interface{}) *syntax.Map {
+	n, ok := v.(*syntax.Map)
+	if !ok {
+		return nil
+	}
+	if len(path) == 0 {
+		return n
+	}
+	for _, entry := range n.Entries {
+		comps, _ := keyComponents(entry.Key)
+		if len(comps) > len(path) || !reflect.DeepEqual(comps, path[:len(comps)]) {
+			continue
+		}
+		switch val := entry.Value.(type) {
+		case *syntax.Map:
+			if m := lookupMap(val, path[len(comps):]); m != nil {
+				return m
+			}
+		case *syntax.List:
+			rest := path[len(comps):]
+			if len(rest) == 0 {
+				continue
+			}
+			if i, ok := rest[0].(int); ok && i < len(val.Items) {
+				if m := lookupMap(val.Items[i], rest[1:]); m != nil {
+					return m
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validate checks the value against the schema and returns any violation.
+func (doc *document) validate(v syntax.Value, schema *Schema, path []interface{}) []Diagnostic {
+	if schema == nil || v == nil {
+		return nil
+	}
+	if alias, ok := v.(*syntax.Alias); ok {
+		return doc.validate(alias.Target, schema, path)
+	}
+
+	report := func(at syntax.Value, format string, args ...interface{}) Diagnostic {
+		s := start(at)
+		return Diagnostic{
+			Range:    Range{Start: doc.position(s), End: doc.lineEnd(s)},
+			Severity: SeverityError,
+			Source:   "boa",
+			Message:  fmt.Sprintf(format, args...),
+		}
+	}
+
+	var (
+		typ   string
+		value interface{}
+	)
+	switch n := v.(type) {
+	case *syntax.Map:
+		typ = "object"
+	case *syntax.List:
+		typ = "array"
+	case *syntax.String:
+		typ, value = "string", n.Value
+	case *syntax.Bool:
+		typ, value = "boolean", n.Value
+	case *syntax.Nil:
+		typ = "null"
+	case *syntax.Number:
+		typ = "number"
+		if c, ok := n.Value.(constant.Value); ok {
+			if c.Kind() == constant.Int {
+				typ = "integer"
+			}
+			value = constant.Val(c)
+		}
+	default:
+		// Format-specific values (e.g. TOML datetimes) are not checked.
+		return nil
+	}
+
+	if !schema.allows(typ) {
+		return []Diagnostic{report(v, "%s: expected %s, got %s", pathString(path), schema.typeString(), typ)}
+	}
+	if len(schema.Enum) != 0 && value != nil {
+		found := false
+		for _, e := range schema.Enum {
+			if fmt.Sprint(e) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return []Diagnostic{report(v, "%s: %v is not one of the allowed values", pathString(path), value)}
+		}
+	}
+
+	var diags []Diagnostic
+	switch n := v.(type) {
+	case *syntax.Map:
+		for _, entry := range n.Entries {
+			comps, _ := keyComponents(entry.Key)
+			sub, full := schema, append([]interface{}(nil), path...)
+			for _, comp := range comps {
+				if name, ok := comp.(string); ok && sub != nil && sub.Closed && sub.Properties[name] == nil {
+					diags = append(diags, report(entry.Key, "%s: unknown key %q", pathString(full), name))
+					sub = nil
+					break
+				}
+				full = append(full, comp)
+				sub = sub.lookup([]interface{}{comp})
+			}
+			diags = append(diags, doc.validate(entry.Value, sub, full)...)
+		}
+	case *syntax.List:
+		for i, item := range n.Items {
+			diags = append(diags, doc.validate(item, schema.Items, append(append([]interface{}(nil), path...), i))...)
+		}
+	}
+	return diags
+}
+
+func pathString(path []interface{}) string {
+	if len(path) == 0 {
+		return "<root>"
+	}
+	var out strings.Builder
+	for i, comp := range path {
+		switch comp := comp.(type) {
+		case int:
+			fmt.Fprintf(&out, "[%d]", comp)
+		default:
+			if i > 0 {
+				out.WriteString(".")
+			}
+			fmt.Fprint(&out, comp)
+		}
+	}
+	return out.String()
+}
+
+// typeCheck decodes the document text into a new value of the specified
+// type, and reports any load error.
+func (doc *document) typeCheck(typ reflect.Type) []Diagnostic {
+	newDecoder, ok := boa.Decoders[doc.ext]
+	if !ok {
+		return nil
+	}
+	err := newDecoder(strings.NewReader(doc.text)).Decode(reflect.New(typ).Interface())
+	if err == nil {
+		return nil
+	}
+	return []Diagnostic{{
+		Range:    doc.errorRange(err),
+		Severity: SeverityError,
+		Source:   "boa",
+		Message:  err.Error(),
+	}}
+}
+
+// reformat returns the document text, reformatted by the encoder of the
+// document's language.
+func (doc *document) reformat() (string, bool) {
+	f, ok := formats[doc.ext]
+	if !ok || f.reformat == nil || doc.err != nil {
+		return "", false
+	}
+	newEncoder, ok := boa.Encoders[doc.ext]
+	if !ok {
+		return "", false
+	}
+	var out bytes.Buffer
+	if err := newEncoder(&out).Option(f.reformat).Encode(doc.ast); err != nil {
+		return "", false
+	}
+	return out.String(), true
+}
+
+// tagParser returns the struct tag parser for the document's language.
+func (doc *document) tagParser() interface{} {
+	return encutil.StructTagParser{Tag: formats[doc.ext].tag}
+}
@@ -0,0 +1,314 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package lsp
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"snai.pe/boa/encoding"
+	"snai.pe/boa/encoding/json5"
+	"snai.pe/boa/internal/reflectutil"
+)
+
+// Schema describes the expected shape of a configuration document.
+//
+// It models the subset of JSON Schema that is useful for completion, hover
+// documentation and basic validation: types, descriptions, defaults, enums,
+// object properties and array items.
+type Schema struct {
+	// Types lists the allowed JSON Schema types ("object", "array",
+	// "string", "number", "integer", "boolean", "null"). An empty slice
+	// allows any type.
+	Types       []string
+	Description string
+	Default     interface{}
+	Enum        []interface{}
+
+	Properties map[string]*Schema
+
+	// AdditionalProperties describes the values of object keys that are
+	// not listed in Properties. A nil value allows any value, unless
+	// Closed is set.
+	AdditionalProperties *Schema
+	Closed               bool
+
+	Items *Schema
+}
+
+// LoadSchema reads a JSON Schema document from r.
+//
+// Local references ("#/definitions/name" and "#/$defs/name") are resolved;
+// other schema keywords not described by the Schema type are ignored.
+func LoadSchema(r io.Reader) (*Schema, error) {
+	var root interface{}
+	if err := json5.NewDecoder(r).Decode(&root); err != nil {
+		return nil, err
+	}
+	return (&schemaLoader{root: root, refs: map[string]*Schema{}}).load(root)
+}
+
+type schemaLoader struct {
+	root interface{}
+	refs map[string]*Schema
+}
+
+func (l *schemaLoader) load(v interface{}) (*Schema, error) {
+	switch v := v.(type) {
+	case bool:
+		// true allows anything; false allows nothing.
+		return &Schema{Closed: !v}, nil
+	case map[interface{}]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			return l.resolve(ref)
+		}
+		var schema Schema
+		if err := l.fill(&schema, v); err != nil {
+			return nil, err
+		}
+		return &schema, nil
+	default:
+		return nil, fmt.Errorf("schema must be an object or a boolean, got %T", v)
+	}
+}
+
+func (l *schemaLoader) resolve(ref string) (*Schema, error) {
+	if schema, ok := l.refs[ref]; ok {
+		return schema, nil
+	}
+	if !strings.HasPrefix(ref, "#/") && ref != "#" {
+		return nil, fmt.Errorf("unsupported non-local schema reference %q", ref)
+	}
+	target := l.root
+	for _, comp := range strings.Split(strings.TrimPrefix(strings.TrimPrefix(ref, "#"), "/"), "/") {
+		if comp == "" {
+			continue
+		}
+		comp = strings.NewReplacer("~1", "/", "~0", "~").Replace(comp)
+		obj, ok := target.(map[interface{}]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unresolvable schema reference %q", ref)
+		}
+		if target, ok = obj[comp]; !ok {
+			return nil, fmt.Errorf("unresolvable schema reference %q", ref)
+		}
+	}
+
+	// Register the schema before filling it in so that recursive references
+	// resolve to the same value.
+	schema := &Schema{}
+	l.refs[ref] = schema
+	obj, ok := target.(map[interface{}]interface{})
+	if !ok {
+		resolved, err := l.load(target)
+		if err != nil {
+			return nil, err
+		}
+		*schema = *resolved
+		return schema, nil
+	}
+	if err := l.fill(schema, obj); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func (l *schemaLoader) fill(schema *Schema, v map[interface{}]interface{}) error {
+	switch t := v["type"].(type) {
+	case string:
+		schema.Types = []string{t}
+	case []interface{}:
+		for _, e := range t {
+			if s, ok := e.(string); ok {
+				schema.Types = append(schema.Types, s)
+			}
+		}
+	}
+	if desc, ok := v["description"].(string); ok {
+		schema.Description = desc
+	} else if title, ok := v["title"].(string); ok {
+		schema.Description = title
+	}
+	schema.Default = v["default"]
+	if enum, ok := v["enum"].([]interface{}); ok {
+		schema.Enum = enum
+	}
+	if props, ok := v["properties"].(map[interface{}]interface{}); ok {
+		schema.Properties = make(map[string]*Schema, len(props))
+		for k, pv := range props {
+			name, ok := k.(string)
+			if !ok {
+				continue
+			}
+			prop, err := l.load(pv)
+			if err != nil {
+				return fmt.Errorf("properties.%s: %w", name, err)
+			}
+			schema.Properties[name] = prop
+		}
+	}
+	if addl, ok := v["additionalProperties"]; ok {
+		if b, ok := addl.(bool); ok {
+			schema.Closed = !b
+		} else {
+			prop, err := l.load(addl)
+			if err != nil {
+				return fmt.Errorf("additionalProperties: %w", err)
+			}
+			schema.AdditionalProperties = prop
+		}
+	}
+	if items, ok := v["items"]; ok {
+		item, err := l.load(items)
+		if err != nil {
+			return fmt.Errorf("items: %w", err)
+		}
+		schema.Items = item
+	}
+	return nil
+}
+
+// schemaFromType builds a schema from a Go type, using the field names that
+// the given naming convention and struct tag parser would produce.
+func schemaFromType(typ reflect.Type, convention encoding.NamingConvention, parser interface{}, seen map[reflect.Type]*Schema) *Schema {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if schema, ok := seen[typ]; ok {
+		return schema
+	}
+
+	schema := &Schema{}
+	switch typ {
+	case reflect.TypeOf(time.Time{}), reflect.TypeOf(url.URL{}), reflect.TypeOf(regexp.Regexp{}), reflect.TypeOf([]byte(nil)):
+		schema.Types = []string{"string"}
+		return schema
+	case reflect.TypeOf(big.Int{}):
+		schema.Types = []string{"integer"}
+		return schema
+	case reflect.TypeOf(big.Float{}), reflect.TypeOf(big.Rat{}):
+		schema.Types = []string{"number"}
+		return schema
+	}
+	if reflectutil.IsValueType(typ) || reflectutil.IsValueType(reflect.PointerTo(typ)) {
+		schema.Types = []string{"string"}
+		return schema
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		schema.Types = []string{"boolean"}
+		schema.Enum = []interface{}{true, false}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		schema.Types = []string{"integer"}
+	case reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		schema.Types = []string{"number"}
+	case reflect.String:
+		schema.Types = []string{"string"}
+	case reflect.Slice, reflect.Array:
+		schema.Types = []string{"array"}
+		seen[typ] = schema
+		schema.Items = schemaFromType(typ.Elem(), convention, parser, seen)
+	case reflect.Map:
+		schema.Types = []string{"object"}
+		seen[typ] = schema
+		schema.AdditionalProperties = schemaFromType(typ.Elem(), convention, parser, seen)
+	case reflect.Struct:
+		schema.Types = []string{"object"}
+		schema.Closed = true
+		seen[typ] = schema
+		fields, _ := reflectutil.VisibleFields(reflect.New(typ).Elem(), convention, parser)
+		schema.Properties = make(map[string]*Schema, len(fields))
+		for _, field := range fields {
+			// Field schemas are copied so that the help text of one field
+			// does not leak into another field sharing the same type.
+			prop := *schemaFromType(field.Type, field.Options.Naming, parser, seen)
+			prop.Description = strings.Join(field.Options.Help, "\n")
+			schema.Properties[field.Options.Name] = &prop
+		}
+	}
+	return schema
+}
+
+// lookup returns the schema that applies to the value at the specified
+// path, or nil if the path is not described by the schema.
+func (schema *Schema) lookup(path []interface{}) *Schema {
+	for _, comp := range path {
+		if schema == nil {
+			return nil
+		}
+		switch comp := comp.(type) {
+		case int:
+			schema = schema.Items
+		case string:
+			if prop, ok := schema.Properties[comp]; ok {
+				schema = prop
+			} else {
+				schema = schema.AdditionalProperties
+			}
+		default:
+			return nil
+		}
+	}
+	return schema
+}
+
+// keys returns the sorted property names of the schema.
+func (schema *Schema) keys() []string {
+	keys := make([]string, 0, len(schema.Properties))
+	for k := range schema.Properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (schema *Schema) allows(typ string) bool {
+	if len(schema.Types) == 0 {
+		return true
+	}
+	for _, t := range schema.Types {
+		if t == typ || t == "number" && typ == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+func (schema *Schema) typeString() string {
+	return strings.Join(schema.Types, " | ")
+}
+
+// markdown renders the documentation of the schema for the specified key.
+func (schema *Schema) markdown(key string) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "**%s**", key)
+	if len(schema.Types) != 0 {
+		fmt.Fprintf(&out, " `%s`", schema.typeString())
+	}
+	if schema.Description != "" {
+		fmt.Fprintf(&out, "\n\n%s", schema.Description)
+	}
+	if schema.Default != nil {
+		fmt.Fprintf(&out, "\n\nDefault: `%v`", schema.Default)
+	}
+	if len(schema.Enum) != 0 {
+		values := make([]string, len(schema.Enum))
+		for i, v := range schema.Enum {
+			values[i] = fmt.Sprintf("`%v`", v)
+		}
+		fmt.Fprintf(&out, "\n\nAllowed values: %s", strings.Join(values, ", "))
+	}
+	return out.String()
+}
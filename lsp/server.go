@@ -0,0 +1,428 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ErrExitWithoutShutdown is returned by Serve when the client sends an exit
+// notification without having requested a shutdown first.
+var ErrExitWithoutShutdown = errors.New("exit notification received before shutdown")
+
+type schemaEntry struct {
+	pattern string
+	schema  *Schema
+	typ     reflect.Type
+}
+
+// Server is a Language Server Protocol server for configuration files in any
+// of the languages supported by boa.
+//
+// It provides diagnostics for syntax errors and, for documents associated
+// with a schema or a Go type, for type errors. It also provides key
+// completion and hover documentation from these schemas, as well as
+// document formatting for the languages that can be reformatted (TOML and
+// JSON5).
+//
+// Formatting is registered for these languages only with the clients that
+// support dynamic registration. Other clients are told that every document
+// can be formatted, and get an error for the other languages.
+type Server struct {
+	schemas []schemaEntry
+	docs    map[string]*document
+	out     io.Writer
+
+	// registerFormatting is set when formatting must be registered with
+	// the client once it is initialized, rather than advertised statically.
+	registerFormatting bool
+	requests           int
+
+	shutdown bool
+}
+
+// NewServer returns a new language server with no registered schema.
+func NewServer() *Server {
+	return &Server{docs: map[string]*document{}}
+}
+
+// RegisterSchema associates a schema with the documents whose file name
+// matches the specified pattern. The pattern syntax is the one of path.Match.
+//
+// When several patterns match a document, the first registered one is used.
+func (s *Server) RegisterSchema(pattern string, schema *Schema) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		panic(fmt.Sprintf("invalid pattern %q: %v", pattern, err))
+	}
+	s.schemas = append(s.schemas, schemaEntry{pattern: pattern, schema: schema})
+}
+
+// RegisterType associates the Go type of v with the documents whose file name
+// matches the specified pattern. The pattern syntax is the one of path.Match.
+//
+// Documents are type-checked by decoding them into a new value of that type,
+// and the `help` tags of the struct fields are used as documentation.
+func (s *Server) RegisterType(pattern string, v interface{}) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		panic(fmt.Sprintf("invalid pattern %q: %v", pattern, err))
+	}
+	typ := reflect.TypeOf(v)
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	s.schemas = append(s.schemas, schemaEntry{pattern: pattern, typ: typ})
+}
+
+func (s *Server) lookupSchema(doc *document) (*Schema, reflect.Type) {
+	name := path.Base(doc.uri)
+	for _, entry := range s.schemas {
+		if ok, _ := path.Match(entry.pattern, name); !ok {
+			continue
+		}
+		if entry.typ == nil {
+			return entry.schema, nil
+		}
+		f, ok := formats[doc.ext]
+		if !ok {
+			return nil, entry.typ
+		}
+		return schemaFromType(entry.typ, f.naming, doc.tagParser(), map[reflect.Type]*Schema{}), entry.typ
+	}
+	return nil, nil
+}
+
+// Serve reads requests from in and writes responses and notifications to out,
+// until the client sends an exit notification or in reaches end of file.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.out = out
+	rd := bufio.NewReader(in)
+	for {
+		data, err := readMessage(rd)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(data, &req); err != nil {
+			if err := writeMessage(out, errorResponse{JSONRPC: "2.0", Error: &responseError{Code: codeParseError, Message: err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "" && req.ID != nil {
+			// Responses to the requests of the server are not needed.
+			continue
+		}
+
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+
+		result, err := s.handle(&req)
+		if req.ID == nil {
+			// Notifications never get a response, even on error.
+			continue
+		}
+		if err != nil {
+			rerr, ok := err.(*responseError)
+			if !ok {
+				rerr = &responseError{Code: codeInternalError, Message: err.Error()}
+			}
+			err = writeMessage(out, errorResponse{JSONRPC: "2.0", ID: req.ID, Error: rerr})
+		} else {
+			err = writeMessage(out, response{JSONRPC: "2.0", ID: req.ID, Result: result})
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(req *request) (interface{}, error) {
+	if s.shutdown && req.Method != "exit" {
+		return nil, &responseError{Code: codeInvalidRequest, Message: "server is shutting down"}
+	}
+
+	unmarshal := func(v interface{}) error {
+		if err := json.Unmarshal(req.Params, v); err != nil {
+			return &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return nil
+	}
+
+	switch req.Method {
+	case "initialize":
+		var params initializeParams
+		if len(req.Params) > 0 {
+			if err := unmarshal(&params); err != nil {
+				return nil, err
+			}
+		}
+		s.registerFormatting = params.Capabilities.TextDocument.Formatting.DynamicRegistration
+		return initializeResult{
+			Capabilities: serverCapabilities{
+				TextDocumentSync:           textDocumentSyncFull,
+				CompletionProvider:         &completionOptions{TriggerCharacters: []string{"."}},
+				HoverProvider:              true,
+				DocumentFormattingProvider: !s.registerFormatting,
+			},
+			ServerInfo: serverInfo{Name: "boa-lsp"},
+		}, nil
+
+	case "initialized":
+		if !s.registerFormatting {
+			return nil, nil
+		}
+		return nil, s.request("client/registerCapability", registrationParams{
+			Registrations: []registration{{
+				ID:              "formatting",
+				Method:          "textDocument/formatting",
+				RegisterOptions: textDocumentRegistrationOptions{DocumentSelector: formattingSelector()},
+			}},
+		})
+
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := unmarshal(&params); err != nil {
+			return nil, err
+		}
+		doc := newDocument(params.TextDocument.URI, params.TextDocument.Text)
+		s.docs[doc.uri] = doc
+		return nil, s.publishDiagnostics(doc)
+
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := unmarshal(&params); err != nil {
+			return nil, err
+		}
+		doc, ok := s.docs[params.TextDocument.URI]
+		if !ok || len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		doc.update(params.ContentChanges[len(params.ContentChanges)-1].Text)
+		return nil, s.publishDiagnostics(doc)
+
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := unmarshal(&params); err != nil {
+			return nil, err
+		}
+		delete(s.docs, params.TextDocument.URI)
+		return nil, writeMessage(s.out, notification{
+			JSONRPC: "2.0",
+			Method:  "textDocument/publishDiagnostics",
+			Params:  publishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []Diagnostic{}},
+		})
+
+	case "textDocument/completion":
+		var params textDocumentPositionParams
+		if err := unmarshal(&params); err != nil {
+			return nil, err
+		}
+		return s.completion(params), nil
+
+	case "textDocument/hover":
+		var params textDocumentPositionParams
+		if err := unmarshal(&params); err != nil {
+			return nil, err
+		}
+		return s.hover(params), nil
+
+	case "textDocument/formatting":
+		var params formattingParams
+		if err := unmarshal(&params); err != nil {
+			return nil, err
+		}
+		return s.formatting(params)
+	}
+
+	if strings.HasPrefix(req.Method, "$/") || req.ID == nil {
+		// Optional notifications and requests may be ignored.
+		return nil, nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q not found", req.Method)}
+}
+
+func (s *Server) diagnostics(doc *document) []Diagnostic {
+	if doc.err != nil {
+		return []Diagnostic{{
+			Range:    doc.errorRange(doc.err),
+			Severity: SeverityError,
+			Source:   "boa",
+			Message:  doc.err.Error(),
+		}}
+	}
+	schema, typ := s.lookupSchema(doc)
+	if typ != nil {
+		return doc.typeCheck(typ)
+	}
+	if schema != nil && doc.ast != nil {
+		return doc.validate(doc.ast.Root, schema, nil)
+	}
+	return nil
+}
+
+func (s *Server) publishDiagnostics(doc *document) error {
+	diags := s.diagnostics(doc)
+	if diags == nil {
+		diags = []Diagnostic{}
+	}
+	return writeMessage(s.out, notification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  publishDiagnosticsParams{URI: doc.uri, Diagnostics: diags},
+	})
+}
+
+func (s *Server) completion(params textDocumentPositionParams) []CompletionItem {
+	items := []CompletionItem{}
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return items
+	}
+	schema, _ := s.lookupSchema(doc)
+	if schema == nil {
+		return items
+	}
+
+	at := doc.cursor(params.Position)
+
+	// The line being edited is usually incomplete; if the document does not
+	// parse, try again without it before falling back to the last known
+	// syntax tree.
+	ast := doc.ast
+	if doc.err != nil {
+		lines := doc.lines()
+		if at.Line <= len(lines) {
+			lines[at.Line-1] = ""
+			if blanked, err := parse(doc.ext, strings.Join(lines, "\n")); err == nil {
+				ast = blanked
+			}
+		}
+	}
+	if ast == nil {
+		return items
+	}
+
+	path := enclosingPath(ast, doc.ext, at)
+	sub := schema.lookup(path)
+	if sub == nil {
+		return items
+	}
+
+	present := map[string]bool{}
+	if m := lookupMap(ast.Root, path); m != nil {
+		for _, entry := range m.Entries {
+			if comps, _ := keyComponents(entry.Key); len(comps) > 0 {
+				if name, ok := comps[0].(string); ok && start(entry.Key).Line != at.Line {
+					present[name] = true
+				}
+			}
+		}
+	}
+
+	for _, key := range sub.keys() {
+		if present[key] {
+			continue
+		}
+		prop := sub.Properties[key]
+		item := CompletionItem{
+			Label:  key,
+			Kind:   CompletionKindProperty,
+			Detail: prop.typeString(),
+		}
+		if prop.Description != "" {
+			item.Documentation = &MarkupContent{Kind: "markdown", Value: prop.Description}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func (s *Server) hover(params textDocumentPositionParams) *Hover {
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok || doc.ast == nil {
+		return nil
+	}
+	schema, _ := s.lookupSchema(doc)
+	if schema == nil {
+		return nil
+	}
+	path, tok := keyAt(doc.ast.Root, nil, doc.cursor(params.Position))
+	if tok == nil {
+		return nil
+	}
+	sub := schema.lookup(path)
+	if sub == nil {
+		return nil
+	}
+	end := tok.End
+	end.Column++
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: sub.markdown(pathString(path))},
+		Range:    &Range{Start: doc.position(tok.Start), End: doc.position(end)},
+	}
+}
+
+func (s *Server) formatting(params formattingParams) ([]TextEdit, error) {
+	edits := []TextEdit{}
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return edits, nil
+	}
+	if formats[doc.ext].reformat == nil {
+		return nil, &responseError{Code: codeRequestFailed, Message: fmt.Sprintf("%s documents cannot be formatted", doc.ext)}
+	}
+	text, ok := doc.reformat()
+	if !ok || text == doc.text {
+		return edits, nil
+	}
+	return append(edits, TextEdit{
+		Range:   Range{Start: Position{}, End: doc.end()},
+		NewText: text,
+	}), nil
+}
+
+// formattingSelector returns the document selector of the languages that
+// can be reformatted.
+func formattingSelector() []documentFilter {
+	exts := make([]string, 0, len(formats))
+	for ext, f := range formats {
+		if f.reformat != nil {
+			exts = append(exts, ext)
+		}
+	}
+	sort.Strings(exts)
+	filters := make([]documentFilter, len(exts))
+	for i, ext := range exts {
+		filters[i] = documentFilter{Pattern: "**/*" + ext}
+	}
+	return filters
+}
+
+// request sends a request to the client. Its response is ignored.
+func (s *Server) request(method string, params interface{}) error {
+	s.requests++
+	return writeMessage(s.out, clientRequest{JSONRPC: "2.0", ID: s.requests, Method: method, Params: params})
+}
@@ -0,0 +1,336 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+type session struct {
+	t   *testing.T
+	in  bytes.Buffer
+	ids int
+}
+
+func (s *session) request(method string, params interface{}) int {
+	s.ids++
+	s.send(map[string]interface{}{"jsonrpc": "2.0", "id": s.ids, "method": method, "params": params})
+	return s.ids
+}
+
+func (s *session) notify(method string, params interface{}) {
+	s.send(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+func (s *session) send(msg interface{}) {
+	if err := writeMessage(&s.in, msg); err != nil {
+		s.t.Fatal(err)
+	}
+}
+
+type reply struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *responseError  `json:"error"`
+}
+
+// run serves the scripted session and returns the responses by request ID,
+// as well as the notifications and requests of the server in the order they
+// were sent.
+func (s *session) run(server *Server) (map[int]reply, []reply) {
+	s.t.Helper()
+	var out bytes.Buffer
+	if err := server.Serve(&s.in, &out); err != nil {
+		s.t.Fatal("serve:", err)
+	}
+
+	responses := map[int]reply{}
+	var notifications []reply
+	rd := bufio.NewReader(&out)
+	for {
+		data, err := readMessage(rd)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		var r reply
+		if err := json.Unmarshal(data, &r); err != nil {
+			s.t.Fatal(err)
+		}
+		if r.ID != nil && r.Method == "" {
+			responses[*r.ID] = r
+		} else {
+			notifications = append(notifications, r)
+		}
+	}
+	return responses, notifications
+}
+
+func open(uri, text string) interface{} {
+	return map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "languageId": "", "version": 1, "text": text},
+	}
+}
+
+func at(uri string, line, char int) interface{} {
+	return map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"position":     Position{Line: line, Character: char},
+	}
+}
+
+type testConfig struct {
+	Name   string `help:"Name of the service."`
+	Server struct {
+		Host string `help:"Address to listen on."`
+		Port int    `help:"Port to listen on."`
+	}
+}
+
+func diagnosticsFor(t *testing.T, notifications []reply, uri string) [][]Diagnostic {
+	t.Helper()
+	var all [][]Diagnostic
+	for _, n := range notifications {
+		if n.Method != "textDocument/publishDiagnostics" {
+			continue
+		}
+		var params publishDiagnosticsParams
+		if err := json.Unmarshal(n.Params, &params); err != nil {
+			t.Fatal(err)
+		}
+		if params.URI == uri {
+			all = append(all, params.Diagnostics)
+		}
+	}
+	return all
+}
+
+func TestDiagnostics(t *testing.T) {
+	s := &session{t: t}
+	s.request("initialize", map[string]interface{}{})
+	s.notify("initialized", map[string]interface{}{})
+	s.notify("textDocument/didOpen", open("file:///app.toml", "name = \"x\"\nport = \n"))
+	s.notify("textDocument/didOpen", open("file:///svc.toml", "name = \"x\"\n[server]\nport = \"eighty\"\n"))
+	s.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": "file:///app.toml", "version": 2},
+		"contentChanges": []interface{}{map[string]interface{}{"text": "name = \"x\"\n"}},
+	})
+	s.request("shutdown", nil)
+	s.notify("exit", nil)
+
+	server := NewServer()
+	server.RegisterType("svc.*", testConfig{})
+	_, notifications := s.run(server)
+
+	app := diagnosticsFor(t, notifications, "file:///app.toml")
+	if len(app) != 2 {
+		t.Fatalf("expected 2 diagnostic notifications for app.toml, got %d", len(app))
+	}
+	if len(app[0]) != 1 {
+		t.Fatalf("expected a syntax error, got %v", app[0])
+	}
+	if r := app[0][0].Range; r.Start.Line != 1 || r.Start.Character != 7 {
+		t.Errorf("syntax error reported at %+v, expected 1:7", r.Start)
+	}
+	if len(app[1]) != 0 {
+		t.Errorf("expected diagnostics to be cleared, got %v", app[1])
+	}
+
+	svc := diagnosticsFor(t, notifications, "file:///svc.toml")
+	if len(svc) != 1 || len(svc[0]) != 1 {
+		t.Fatalf("expected a type error for svc.toml, got %v", svc)
+	}
+	if r := svc[0][0].Range; r.Start.Line != 2 {
+		t.Errorf("type error reported at %+v, expected line 2", r.Start)
+	}
+	if !strings.Contains(svc[0][0].Message, "cannot load value into int") {
+		t.Errorf("type error %q does not mention the expected type", svc[0][0].Message)
+	}
+}
+
+func TestSchemaDiagnostics(t *testing.T) {
+	schema, err := LoadSchema(strings.NewReader(`{
+		type: "object",
+		additionalProperties: false,
+		properties: {
+			level: {enum: ["debug", "info"]},
+			port: {type: "integer"},
+		},
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &session{t: t}
+	s.notify("textDocument/didOpen", open("file:///app.yaml", "level: trace\nport: 80\nextra: 1\n"))
+
+	server := NewServer()
+	server.RegisterSchema("app.yaml", schema)
+	_, notifications := s.run(server)
+
+	diags := diagnosticsFor(t, notifications, "file:///app.yaml")
+	if len(diags) != 1 || len(diags[0]) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %v", diags)
+	}
+	if diags[0][0].Range.Start.Line != 0 || !strings.Contains(diags[0][0].Message, "allowed values") {
+		t.Errorf("unexpected diagnostic %+v", diags[0][0])
+	}
+	if diags[0][1].Range.Start.Line != 2 || !strings.Contains(diags[0][1].Message, `unknown key "extra"`) {
+		t.Errorf("unexpected diagnostic %+v", diags[0][1])
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		uri  string
+		text string
+		line int
+		char int
+		want []string
+	}{
+		{"toml-root", "file:///svc.toml", "\n[server]\nport = 1\n", 0, 0, []string{"name"}},
+		{"toml-table", "file:///svc.toml", "name = \"x\"\n[server]\nport = 1\n\n", 3, 0, []string{"host"}},
+		{"yaml-nested", "file:///svc.yaml", "server:\n  port: 1\n  \n", 2, 2, []string{"host"}},
+		{"yaml-partial", "file:///svc.yaml", "server:\n  port: 1\n  ho\nname: x\n", 2, 4, []string{"host"}},
+		{"json5-nested", "file:///svc.json5", "{\n  server: {\n    \n  },\n}\n", 2, 4, []string{"host", "port"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &session{t: t}
+			s.notify("textDocument/didOpen", open(tc.uri, tc.text))
+			id := s.request("textDocument/completion", at(tc.uri, tc.line, tc.char))
+
+			server := NewServer()
+			server.RegisterType("svc.*", testConfig{})
+			responses, _ := s.run(server)
+
+			var items []CompletionItem
+			if err := json.Unmarshal(responses[id].Result, &items); err != nil {
+				t.Fatal(err)
+			}
+			var labels []string
+			for _, item := range items {
+				labels = append(labels, item.Label)
+			}
+			if strings.Join(labels, ",") != strings.Join(tc.want, ",") {
+				t.Errorf("expected completions %v, got %v", tc.want, labels)
+			}
+		})
+	}
+}
+
+func TestHover(t *testing.T) {
+	s := &session{t: t}
+	s.notify("textDocument/didOpen", open("file:///svc.toml", "[server]\nport = 80\n"))
+	id := s.request("textDocument/hover", at("file:///svc.toml", 1, 2))
+	none := s.request("textDocument/hover", at("file:///svc.toml", 1, 8))
+
+	server := NewServer()
+	server.RegisterType("svc.*", testConfig{})
+	responses, _ := s.run(server)
+
+	var hover Hover
+	if err := json.Unmarshal(responses[id].Result, &hover); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(hover.Contents.Value, "server.port") || !strings.Contains(hover.Contents.Value, "Port to listen on.") {
+		t.Errorf("unexpected hover contents %q", hover.Contents.Value)
+	}
+	if hover.Range == nil || *hover.Range != (Range{Start: Position{1, 0}, End: Position{1, 4}}) {
+		t.Errorf("unexpected hover range %+v", hover.Range)
+	}
+	if string(responses[none].Result) != "null" {
+		t.Errorf("expected no hover on a value, got %s", responses[none].Result)
+	}
+}
+
+func TestFormatting(t *testing.T) {
+	s := &session{t: t}
+	s.notify("textDocument/didOpen", open("file:///app.toml", "a   =    1\n"))
+	id := s.request("textDocument/formatting", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": "file:///app.toml"},
+		"options":      map[string]interface{}{"tabSize": 2, "insertSpaces": true},
+	})
+	unknown := s.request("workspace/unknown", nil)
+
+	responses, _ := s.run(NewServer())
+
+	var edits []TextEdit
+	if err := json.Unmarshal(responses[id].Result, &edits); err != nil {
+		t.Fatal(err)
+	}
+	if len(edits) != 1 || edits[0].NewText != "a = 1\n" {
+		t.Errorf("unexpected formatting edits %+v", edits)
+	}
+	if err := responses[unknown].Error; err == nil || err.Code != codeMethodNotFound {
+		t.Errorf("expected a method not found error, got %+v", err)
+	}
+}
+
+func TestFormattingRegistration(t *testing.T) {
+	s := &session{t: t}
+	initialize := s.request("initialize", map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			"textDocument": map[string]interface{}{
+				"formatting": map[string]interface{}{"dynamicRegistration": true},
+			},
+		},
+	})
+	s.notify("initialized", map[string]interface{}{})
+	s.notify("textDocument/didOpen", open("file:///app.yaml", "a:   1\n"))
+	yaml := s.request("textDocument/formatting", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": "file:///app.yaml"},
+	})
+
+	responses, messages := s.run(NewServer())
+
+	var result initializeResult
+	if err := json.Unmarshal(responses[initialize].Result, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Capabilities.DocumentFormattingProvider {
+		t.Errorf("formatting must not be advertised statically to clients that register it dynamically")
+	}
+
+	// Formatting is only registered for the languages that can be
+	// reformatted.
+	var params struct {
+		Registrations []struct {
+			Method          string
+			RegisterOptions textDocumentRegistrationOptions
+		}
+	}
+	for _, msg := range messages {
+		if msg.Method == "client/registerCapability" {
+			if err := json.Unmarshal(msg.Params, &params); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if len(params.Registrations) != 1 || params.Registrations[0].Method != "textDocument/formatting" {
+		t.Fatalf("expected formatting to be registered, got %+v", params)
+	}
+	var patterns []string
+	for _, filter := range params.Registrations[0].RegisterOptions.DocumentSelector {
+		patterns = append(patterns, filter.Pattern)
+	}
+	if expected := "**/*.json **/*.json5 **/*.toml"; strings.Join(patterns, " ") != expected {
+		t.Errorf("expected document selector %q, got %q", expected, patterns)
+	}
+
+	if err := responses[yaml].Error; err == nil || err.Code != codeRequestFailed {
+		t.Errorf("expected YAML documents to not be formatted, got %+v", err)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"snai.pe/boa/internal/fsutil"
+)
+
+// ErrConflict is returned by Modify when the configuration file was changed
+// by another program while it was being modified.
+var ErrConflict = errors.New("file was modified concurrently")
+
+// Modify loads the named configuration file into the value pointed to by v,
+// calls modify, and saves v back into the file, while holding an advisory
+// lock on it. This lets several processes, such as a GUI, a CLI and a
+// daemon, change the same file without losing each other's updates:
+//
+//	var config Config
+//	err := boa.Modify("myapp.toml", &config, func() error {
+//		config.Recent = append(config.Recent, path)
+//		return nil
+//	})
+//
+// The name is interpreted relative to the return value of ConfigHome(), or to
+// the FS set by SetConfigHomeFS if it is a WritableFS, in the same way as Save
+// does. The advisory lock is only taken on the files of directories. Unlike
+// Load, only that file is loaded, without the other search paths or the
+// environment, so that v holds the contents of the file. If it does not
+// exist, v is left untouched before modify is called.
+//
+// Processes that do not take the lock, such as text editors, may still
+// change the file. If its contents changed by the time v is saved, the file
+// is left untouched, and an error wrapping ErrConflict is returned. Changes
+// are detected with a hash of the contents, since modification times are too
+// coarse on some filesystems. If modify returns an error, the file is left
+// untouched, and the error is returned.
+//
+// Save only waits for the lock if the Locking option is set.
+func Modify(name string, v interface{}, modify func() error) error {
+	return modifyFile("Modify", configHomeWritableFS, name, v, modify)
+}
+
+// ModifyState is the same as Modify, for the state files of StateHome(),
+// as LoadState and SaveState are for Load and Save.
+func ModifyState(name string, v interface{}, modify func() error) error {
+	return modifyFile("ModifyState", stateHomeWritableFS, name, v, modify)
+}
+
+func modifyFile(fn string, home func() (WritableFS, error), name string, v interface{}, modify func() error) error {
+	checkSaveName(fn, name)
+	fsys, err := home()
+	if err != nil {
+		return err
+	}
+	loader := defaultLoader()
+	newEncoder, opts, err := loader.fileEncoder(name)
+	if err != nil {
+		return err
+	}
+	ext := filepath.Ext(name)
+	newDecoder, ok := loader.decoders[ext]
+	if !ok {
+		return fmt.Errorf("no known decoder for file extension %q", ext)
+	}
+
+	if dir, ok := fsys.(dirFS); ok {
+		path := filepath.Join(dir.Dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil && !errors.Is(err, os.ErrExist) {
+			return err
+		}
+		unlock, err := fsutil.Lock(path)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	name = filepath.ToSlash(name)
+	orig, err := readVersion(fsys, name)
+	if err != nil {
+		return err
+	}
+	if orig.exists {
+		// Only the common options apply, since the others populate v from
+		// other sources than the file, which must not be saved into it.
+		var common []interface{}
+		for _, opt := range loader.decoderOptions {
+			if _, ok := opt.(CommonOption); ok {
+				common = append(common, opt)
+			}
+		}
+		if err := newDecoder(bytes.NewReader(orig.data)).Option(common...).Decode(v); err != nil {
+			return err
+		}
+	}
+
+	if err := modify(); err != nil {
+		return err
+	}
+
+	cur, err := readVersion(fsys, name)
+	if err != nil {
+		return err
+	}
+	if !cur.same(orig) {
+		return fmt.Errorf("%s: %w", joinPath(fsys, name), ErrConflict)
+	}
+	// The lock is already held.
+	return writeFile(fsys, name, opts.Backups, false, func(w io.Writer) error {
+		return newEncoder(w).Option(loader.encoderOptions...).Encode(v)
+	})
+}
+
+// fileVersion identifies the contents of a file at some point in time.
+type fileVersion struct {
+	exists bool
+	data   []byte
+	sum    [sha256.Size]byte
+}
+
+func readVersion(fsys fs.FS, name string) (fileVersion, error) {
+	var version fileVersion
+	data, err := fs.ReadFile(fsys, name)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return version, nil
+	case err != nil:
+		return version, err
+	}
+	version.exists = true
+	version.data = data
+	version.sum = sha256.Sum256(data)
+	return version, nil
+}
+
+// same returns whether the file is unchanged between both versions. Files
+// that were written again with the same contents are considered unchanged.
+func (version fileVersion) same(other fileVersion) bool {
+	return version.exists == other.exists && version.sum == other.sum
+}
@@ -0,0 +1,109 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestModify(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("XDG configuration paths require a unix system")
+	}
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+
+	type Config struct {
+		Count int
+		Name  string
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var config Config
+			err := Modify("myapp.toml", &config, func() error {
+				config.Count++
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var config Config
+	if err := Modify("myapp.toml", &config, func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if config.Count != 8 {
+		t.Fatalf("expected count to be 8, got %d", config.Count)
+	}
+
+	path := filepath.Join(home, "myapp.toml")
+	failed := errors.New("failed")
+	err := Modify("myapp.toml", &config, func() error {
+		config.Name = "unsaved"
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("expected %v, got %v", failed, err)
+	}
+
+	err = Modify("myapp.toml", &config, func() error {
+		config.Name = "conflict"
+		return os.WriteFile(path, []byte("count = 42\n"), 0666)
+	})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected %v, got %v", ErrConflict, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "count = 42\n" {
+		t.Fatalf("expected the concurrent change to be kept, got %q", data)
+	}
+}
+
+func TestModifyWritableFS(t *testing.T) {
+	type Config struct {
+		Count int
+	}
+
+	m := NewMemFS(nil)
+	SetConfigHomeFS(m)
+	defer SetConfigHomeFS(nil)
+
+	// Modify changes the file that Save saved.
+	if err := Save("myapp.toml", Config{Count: 1}); err != nil {
+		t.Fatal(err)
+	}
+	var config Config
+	err := Modify("myapp.toml", &config, func() error {
+		config.Count++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := fs.ReadFile(m, "myapp.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "count = 2\n" {
+		t.Fatalf("unexpected contents %q", data)
+	}
+}
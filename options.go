@@ -7,6 +7,7 @@ package boa
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"snai.pe/boa/encoding"
@@ -42,6 +43,46 @@ func LineBreak(lb string) EncoderOption {
 	}
 }
 
+// Template returns an encoder option that makes the encoder write a fully
+// commented configuration template for the encoded value, rather than the
+// value itself.
+//
+// Every field is present in the template, preceded by its help text, type,
+// default value, and the environment variables that can set it when the
+// configuration is loaded with AutomaticEnv(envPrefix). Fields with a nil
+// value are considered optional and are commented out. If envPrefix is
+// empty, only the variables of fields with an `env` or `envPrefix` tag are
+// documented.
+func Template(envPrefix string) EncoderOption {
+	return func(opts *encoding.EncoderOptions) {
+		opts.Template = true
+		opts.EnvPrefix = envPrefix
+	}
+}
+
+// Backups returns an encoder option that makes Save keep the specified number
+// of backups of the configuration files it replaces. With a single backup,
+// the previous version of app.toml is kept as app.toml~. With more, the
+// previous versions are kept as app.toml.1 (the most recent), app.toml.2,
+// and so on, and the oldest ones are removed.
+func Backups(n int) EncoderOption {
+	if n < 0 {
+		panic("number of backups must not be negative.")
+	}
+	return func(opts *encoding.EncoderOptions) {
+		opts.Backups = n
+	}
+}
+
+// Locking returns an encoder option that makes Save take an advisory lock on
+// the configuration file while it is replaced, so that it waits for other
+// processes that modify the file with Modify.
+func Locking(enabled bool) EncoderOption {
+	return func(opts *encoding.EncoderOptions) {
+		opts.Lock = enabled
+	}
+}
+
 // NamingConvention returns an option that sets the default naming convention
 // of an encoder or decoder to the specified convention.
 //
@@ -62,18 +103,45 @@ func LineBreak(lb string) EncoderOption {
 //   - "flatcase"
 //   - "UPPERFLATCASE"
 func NamingConvention(name interface{}) CommonOption {
-	var convention encoding.NamingConvention
+	convention := namingConvention(name)
+	return func(opts *encoding.CommonOptions) {
+		opts.NamingConvention = convention
+	}
+}
+
+func namingConvention(name interface{}) encoding.NamingConvention {
 	switch v := name.(type) {
 	case string:
-		convention = encoding.NamingConventionByName(v)
+		return encoding.NamingConventionByName(v)
 	case encoding.NamingConvention:
-		convention = v
+		return v
 	default:
 		panic(fmt.Sprintf("%T is neither a naming convention, or a naming convention name.", name))
 	}
+}
 
+// EnvNamingConvention returns an option that sets the naming convention of
+// the keys that make up environment variable names, in the same way that
+// NamingConvention does for configuration keys.
+//
+// The default is "SCREAMING_SNAKE_CASE".
+func EnvNamingConvention(name interface{}) CommonOption {
+	convention := namingConvention(name)
 	return func(opts *encoding.CommonOptions) {
-		opts.NamingConvention = convention
+		opts.EnvNamingConvention = convention
+	}
+}
+
+// EnvSeparator returns an option that sets the separator that is inserted
+// between the components of the environment variable names of nested values.
+//
+// The default is "_", with which PREFIX_HTTP_SERVER_PORT could either refer
+// to http-server.port or http.server-port. With a separator of "__", these
+// keys are respectively set by PREFIX__HTTP_SERVER__PORT and
+// PREFIX__HTTP__SERVER_PORT.
+func EnvSeparator(sep string) CommonOption {
+	return func(opts *encoding.CommonOptions) {
+		opts.EnvSeparator = sep
 	}
 }
 
@@ -91,8 +159,8 @@ func AutomaticEnv(prefix string) DecoderOption {
 // substitution, either by fields marked with an `env` tag, or fields
 // implicitly matching variables via AutomaticEnv.
 //
-// Incompatible with EnvironFunc. Setting Environ after EnvironFunc
-// overrides the lookup function that EnvironFunc previously set.
+// Incompatible with EnvironFunc and EnvironFuncs. Setting Environ after them
+// overrides the lookup functions that they previously set.
 func Environ(env []string) DecoderOption {
 	environ := make(map[string]string, len(env))
 	for _, e := range env {
@@ -107,18 +175,124 @@ func Environ(env []string) DecoderOption {
 		v, ok := environ[k]
 		return v, ok
 	}
+	listEnv := func() []string {
+		return env
+	}
 
-	return EnvironFunc(lookupEnv)
+	return EnvironFuncs(lookupEnv, listEnv)
 }
 
 // EnvironFunc sets the lookup function for environment variables. By default,
 // os.LookupEnv is used.
 //
-// Incompatible with EnvironFunc. Setting EnvironFunc after Environ
-// overrides the variables that Environ previously set.
+// With EnvCollections, the environment variables are listed by looking up
+// the names of the variables of os.Environ with fn, so that both read the
+// same values. Use EnvironFuncs if fn also knows other variables.
+//
+// Incompatible with Environ and EnvironFuncs. Setting EnvironFunc after
+// them overrides the variables that they previously set.
 func EnvironFunc(fn func(string) (string, bool)) DecoderOption {
+	listEnv := func() []string {
+		var env []string
+		for _, e := range os.Environ() {
+			k := strings.SplitN(e, "=", 2)[0]
+			if v, ok := fn(k); ok {
+				env = append(env, k+"="+v)
+			}
+		}
+		return env
+	}
+	return EnvironFuncs(fn, listEnv)
+}
+
+// EnvironFuncs sets the lookup function for environment variables, along
+// with the function that lists all of them in key=value form, which is used
+// by EnvCollections. Both functions must read the same variables. By
+// default, os.LookupEnv and os.Environ are used.
+//
+// Incompatible with Environ and EnvironFunc. Setting EnvironFuncs after
+// them overrides the variables that they previously set.
+func EnvironFuncs(lookup func(string) (string, bool), list func() []string) DecoderOption {
+	return func(opts *encoding.DecoderOptions) {
+		opts.LookupEnv = lookup
+		opts.ListEnv = list
+	}
+}
+
+// StructuredEnv enables the population of lists, maps, and structs from
+// environment variables whose value is written inline in the configuration
+// language of the specified file extension, such as JSON5 or YAML flow
+// syntax:
+//
+//	APP_PEERS='[{host: "a", port: 1}, {host: "b", port: 2}]'
+//
+// Only the values that start with '[' or '{' are parsed this way. They are
+// loaded with the same semantics as configuration files, which means that
+// maps and structs are merged with the values that were previously loaded.
+// The variables of nested values still take precedence: for instance,
+// APP_SERVER_PORT overrides the port in APP_SERVER='{host: "a", port: 1}'.
+//
+// The language is one of the languages of the Decoder or Loader that the
+// option is passed to, or one of Decoders when it is passed directly to the
+// decoder of a specific language. Decoding fails if it is unknown.
+func StructuredEnv(ext string) DecoderOption {
+	return func(opts *encoding.DecoderOptions) {
+		opts.EnvFormat = ext
+		opts.EnvDecoder = Decoders[ext]
+	}
+}
+
+// EnvCollections enables the creation of list elements and map entries from
+// environment variables. By default, only the elements and entries that
+// already exist are populated from the environment.
+//
+// With this option, the environment is scanned for variables that are
+// nested under the variable name of a list or map. For instance, with
+// AutomaticEnv("APP"), APP_UPSTREAMS_0_HOST sets the host of the first
+// element of Upstreams, and APP_LABELS_team sets the "team" entry of Labels.
+//
+// Map keys are taken verbatim from variable names, up to the next separator
+// (see EnvSeparator). New list elements can only be created at the end of
+// lists: the indices of a list must be lower than its length plus the number
+// of new elements.
+func EnvCollections() DecoderOption {
+	return func(opts *encoding.DecoderOptions) {
+		opts.EnvCollections = true
+	}
+}
+
+// ListSeparator returns an option that sets the separator of the elements of
+// lists that are set from a single string: from an environment variable, or
+// from a string in a configuration file where a list is expected. For
+// instance, with ListSeparator(","), APP_ORIGINS=a.com,b.com sets Origins to
+// []string{"a.com", "b.com"}.
+//
+// The `sep` struct tag overrides it for the lists within a field:
+//
+//	Origins []string `sep:","`
+//
+// The default is os.PathListSeparator for environment variables. Strings in
+// configuration files are only split into lists when ListSeparator or the
+// `sep` tag is set.
+func ListSeparator(sep string) DecoderOption {
+	if sep == "" {
+		panic("list separator must not be empty.")
+	}
+	return func(opts *encoding.DecoderOptions) {
+		opts.ListSeparator = sep
+	}
+}
+
+// Format returns a decoder option that sets the configuration language of
+// the inputs whose name does not have a known extension, such as the
+// standard input, by file extension. For instance, with Format(".toml"),
+// NewDecoder(os.Stdin) decodes TOML.
+//
+// Without this option, the configuration language of these inputs is
+// detected from their contents (see DetectFormat).
+func Format(ext string) DecoderOption {
 	return func(opts *encoding.DecoderOptions) {
-		opts.LookupEnv = fn
+		opts.Format = ext
 	}
 }
 
@@ -7,7 +7,6 @@ package boa
 
 import (
 	"io/fs"
-	"os"
 )
 
 // ConfigHome returns the filesystem path to the current user's configuration
@@ -41,6 +40,74 @@ func SystemConfigDirs() []string {
 	return configDirs()
 }
 
+// DataHome returns the filesystem path to the current user's data home, or
+// an error if there is none.
+//
+// The data home of a user contains the data files of the programs used by that
+// user, which are not configuration, but should not be discarded, like
+// downloaded plugins or fonts. Typical values per OS are:
+//
+//   - Linux & UNIX derivatives:  ~/.local/share  ($XDG_DATA_HOME)
+//   - macOS:                     ~/Library/Application Support
+//   - Windows:                   C:\Users\<user>\AppData\Roaming (%APPDATA%)
+func DataHome() (string, error) {
+	return dataHome()
+}
+
+// StateHome returns the filesystem path to the current user's state home, or
+// an error if there is none.
+//
+// The state home of a user contains the data of the programs used by that user
+// that should persist between restarts, but that is not important enough to be
+// kept with the data files, like the history of actions, or the layout of
+// windows. Typical values per OS are:
+//
+//   - Linux & UNIX derivatives:  ~/.local/state  ($XDG_STATE_HOME)
+//   - macOS:                     ~/Library/Application Support
+//   - Windows:                   C:\Users\<user>\AppData\Local (%LOCALAPPDATA%)
+func StateHome() (string, error) {
+	return stateHome()
+}
+
+// CacheHome returns the filesystem path to the current user's cache home, or
+// an error if there is none.
+//
+// The cache home of a user contains the non-essential data of the programs used
+// by that user, which may be deleted at any time. Typical values per OS are:
+//
+//   - Linux & UNIX derivatives:  ~/.cache  ($XDG_CACHE_HOME)
+//   - macOS:                     ~/Library/Caches
+//   - Windows:                   C:\Users\<user>\AppData\Local (%LOCALAPPDATA%)
+func CacheHome() (string, error) {
+	return cacheHome()
+}
+
+// RuntimeDir returns the filesystem path to the current user's runtime
+// directory, or an error if there is none.
+//
+// The runtime directory of a user contains the files of the programs used by
+// that user that do not outlive the session of the user, like sockets and
+// named pipes. Typical values per OS are:
+//
+//   - Linux & UNIX derivatives:  /run/user/<uid>  ($XDG_RUNTIME_DIR)
+//   - macOS:                     $TMPDIR
+//   - Windows:                   C:\Users\<user>\AppData\Local\Temp (%TEMP%)
+func RuntimeDir() (string, error) {
+	return runtimeDir()
+}
+
+// SystemDataDirs returns the filesystem paths to the system data directories,
+// in order of most important to least important.
+//
+// The returned paths are OS-specific. Typical values per OS are:
+//
+//   - Linux & UNIX derivatives:  /usr/local/share, /usr/share ($XDG_DATA_DIRS)
+//   - macOS:                     /Library/Application Support
+//   - Windows:                   C:\ProgramData
+func SystemDataDirs() []string {
+	return dataDirs()
+}
+
 // ConfigPaths returns, in order of least important to most important, the
 // paths that may hold configuration files for the current user.
 //
@@ -56,18 +123,7 @@ func SystemConfigDirs() []string {
 //   - macOS:                     /Library/Preferences, ~/Library/Preferences
 //   - Windows:                   C:\ProgramData, C:\Users\<user>\AppData\Roaming
 func ConfigPaths() []fs.FS {
-	paths := configDirs()
-	fs := make([]fs.FS, 0, len(paths)+2)
-	if defaultPath != nil {
-		fs = append(fs, defaultPath)
-	}
-	for _, path := range paths {
-		fs = append(fs, os.DirFS(path))
-	}
-	if cfs := ConfigHomeFS(); cfs != nil {
-		fs = append(fs, cfs)
-	}
-	return fs
+	return defaultLoader().SearchPaths()
 }
 
 var defaultPath fs.FS
@@ -83,20 +139,46 @@ var configHomeFS fs.FS
 
 // SetConfigHomeFS overrides the user configuration home, which gets added as
 // the most important path in the slice returned by ConfigPaths.
+//
+// If f is a WritableFS, such as a MemFS, Save saves the configuration files
+// into it rather than into ConfigHome().
 func SetConfigHomeFS(f fs.FS) {
 	configHomeFS = f
 }
 
+// configHomeWritableFS returns the WritableFS in which Save saves the
+// configuration files.
+func configHomeWritableFS() (WritableFS, error) {
+	if f, ok := configHomeFS.(WritableFS); ok {
+		return f, nil
+	}
+	home, err := ConfigHome()
+	if err != nil {
+		return nil, err
+	}
+	return NewDirFS(home), nil
+}
+
+// stateHomeWritableFS returns the WritableFS in which SaveState saves the
+// state files.
+func stateHomeWritableFS() (WritableFS, error) {
+	home, err := StateHome()
+	if err != nil {
+		return nil, err
+	}
+	return NewDirFS(home), nil
+}
+
 // ConfigHomeFS returns the fs.FS for the user's configuration home.
 //
-// By default, it returns os.DirFS(ConfigHome()) (or nil if unsuccessful),
+// By default, it returns NewDirFS(ConfigHome()) (or nil if unsuccessful),
 // unless SetConfigHomeFS has been called, in which case the FS that was set
 // by the function is returned.
 func ConfigHomeFS() fs.FS {
 	if configHomeFS == nil {
 		path, err := configHome()
 		if err == nil {
-			configHomeFS = os.DirFS(path)
+			configHomeFS = NewDirFS(path)
 		}
 	}
 	return configHomeFS
@@ -7,6 +7,7 @@ package boa
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 )
@@ -31,3 +32,32 @@ func configPaths() []string {
 	}
 	return paths
 }
+
+func libraryDir(what string, elems ...string) (string, error) {
+	home := os.Getenv("HOME")
+	if home == "" {
+		return "", fmt.Errorf("could not find the user %s: no $HOME set", what)
+	}
+	return filepath.Join(append([]string{home, "Library"}, elems...)...), nil
+}
+
+func dataHome() (string, error) {
+	return libraryDir("data home", "Application Support")
+}
+
+func stateHome() (string, error) {
+	return libraryDir("state home", "Application Support")
+}
+
+func cacheHome() (string, error) {
+	return libraryDir("cache home", "Caches")
+}
+
+func runtimeDir() (string, error) {
+	// $TMPDIR is specific to each user on macOS.
+	return os.TempDir(), nil
+}
+
+func dataDirs() []string {
+	return []string{"/Library/Application Support"}
+}
@@ -30,3 +30,23 @@ func configPaths() []string {
 	}
 	return paths
 }
+
+func dataHome() (string, error) {
+	return xdg.DataHome()
+}
+
+func stateHome() (string, error) {
+	return xdg.StateHome()
+}
+
+func cacheHome() (string, error) {
+	return xdg.CacheHome()
+}
+
+func runtimeDir() (string, error) {
+	return xdg.RuntimeDir()
+}
+
+func dataDirs() []string {
+	return xdg.DataDirs()
+}
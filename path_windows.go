@@ -7,6 +7,7 @@ package boa
 
 import (
 	"errors"
+	"fmt"
 	"os"
 )
 
@@ -30,3 +31,32 @@ func configPaths() []string {
 	}
 	return paths
 }
+
+func dataHome() (string, error) {
+	return configHome()
+}
+
+func localAppData(what string) (string, error) {
+	localAppData := os.Getenv("LOCALAPPDATA")
+	if localAppData == "" {
+		return "", fmt.Errorf("could not find the user %s: no %%LOCALAPPDATA%% set", what)
+	}
+	return localAppData, nil
+}
+
+func stateHome() (string, error) {
+	return localAppData("state home")
+}
+
+func cacheHome() (string, error) {
+	return localAppData("cache home")
+}
+
+func runtimeDir() (string, error) {
+	// %TEMP% is specific to each user on Windows.
+	return os.TempDir(), nil
+}
+
+func dataDirs() []string {
+	return configDirs()
+}
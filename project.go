@@ -0,0 +1,100 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProjectPaths returns, in order of least important to most important, the
+// paths that may hold the project-local configuration files of start, which
+// are start and its parent directories. If start is empty, the working
+// directory is used.
+//
+// The parent directories are walked up to the filesystem root, or to the
+// first directory that contains one of the specified markers, such as ".git",
+// inclusively. Closer directories are more important, and all of them are
+// meant to be more important than ConfigPaths:
+//
+//	paths, err := boa.ProjectPaths("", ".git")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	f := boa.Open("myapp", append(boa.ConfigPaths(), paths...)...)
+//
+// In these paths, configuration files and drop-in directories may also be
+// hidden, with a dot prepended to their name, like .myapp.toml or .myapp.d.
+// If both exist in the same directory, the one without the dot is used.
+func ProjectPaths(start string, markers ...string) ([]fs.FS, error) {
+	if start == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		start = wd
+	}
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []fs.FS
+	for {
+		paths = append(paths, projectFS{Dir: dir, FS: os.DirFS(dir)})
+		if hasMarker(dir, markers) {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	// Order from least to most important.
+	for i, j := 0, len(paths)-1; i < j; i, j = i+1, j-1 {
+		paths[i], paths[j] = paths[j], paths[i]
+	}
+	return paths, nil
+}
+
+func hasMarker(dir string, markers []string) bool {
+	for _, marker := range markers {
+		if _, err := os.Lstat(filepath.Join(dir, marker)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// projectFS is a project directory, in which the files at the top level can
+// also be opened from their hidden name.
+type projectFS struct {
+	Dir string
+	FS  fs.FS
+}
+
+func (f projectFS) Open(name string) (fs.File, error) {
+	return f.FS.Open(f.realName(name))
+}
+
+// realName returns the hidden name of name if only the hidden file exists.
+func (f projectFS) realName(name string) string {
+	if !fs.ValidPath(name) || name == "." || strings.HasPrefix(name, ".") {
+		return name
+	}
+	if _, err := fs.Stat(f.FS, name); !errors.Is(err, fs.ErrNotExist) {
+		return name
+	}
+	if _, err := fs.Stat(f.FS, "."+name); err == nil {
+		return "." + name
+	}
+	return name
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProjectPaths(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("project/.git/HEAD", "ref: refs/heads/main\n")
+	write("project/myapp.yaml", "name: project\nport: 80\nhost: localhost\n")
+	write("project/sub/.myapp.toml", "name = \"sub\"\n")
+	write("project/sub/.myapp.d/10-port.toml", "port = 8080\n")
+	write("project/sub/deeper/README", "")
+	write("myapp.toml", "host = \"outside\"\n")
+
+	start := filepath.Join(root, "project/sub/deeper")
+	paths, err := ProjectPaths(start, ".git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dirs []string
+	for _, p := range paths {
+		dirs = append(dirs, fsPath(p))
+	}
+	expected := []string{
+		filepath.Join(root, "project"),
+		filepath.Join(root, "project/sub"),
+		filepath.Join(root, "project/sub/deeper"),
+	}
+	if strings.Join(dirs, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected paths %q, got %q", expected, dirs)
+	}
+
+	var config struct {
+		Name string
+		Host string
+		Port int
+	}
+	f := Open("myapp", paths...)
+	defer f.Close()
+	if err := NewLoader().NewDecoder(f).Decode(&config); err != nil {
+		t.Fatal(err)
+	}
+	if config.Name != "sub" || config.Port != 8080 || config.Host != "localhost" {
+		t.Fatalf("unexpected config %+v", config)
+	}
+	used := f.Used()
+	if len(used) != 3 || !strings.HasSuffix(used[1], "/.myapp.toml") || !strings.HasSuffix(used[2], "/.myapp.d/10-port.toml") {
+		t.Fatalf("unexpected used files %q", used)
+	}
+
+	config.Host = ""
+	err = NewLoader().Paths().Project(start, ".git").Files("myapp").Load(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Host != "localhost" {
+		t.Fatalf("expected localhost, got %q", config.Host)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"snai.pe/boa/encoding"
+	"snai.pe/boa/internal/encutil"
+	"snai.pe/boa/syntax"
+)
+
+// ParseOverrides parses key path assignments, such as the arguments of
+// repeated `--set` command-line flags, into a document that the Overrides
+// option applies on top of the configuration.
+//
+// Each argument has the form `path=value`, where path is made of keys
+// separated by dots and of list indices between brackets, like
+// `servers[0].port=8080`. Keys are named like in configuration files, and a
+// backslash escapes the next character of a key, like a dot or a bracket.
+//
+// The values are converted into nodes by parseValue, which lets a
+// configuration language determine their type in values of interface types;
+// for instance, with yaml.YAML1_2.ResolveScalar, `debug=true` sets a bool. If
+// parseValue is nil, values are strings. Values are then converted to the
+// type of the fields that they are set on, like environment variables are.
+func ParseOverrides(args []string, parseValue func(string) (syntax.Value, error)) (*syntax.Document, error) {
+	if parseValue == nil {
+		parseValue = func(s string) (syntax.Value, error) {
+			return &syntax.String{Value: s}, nil
+		}
+	}
+
+	root := &syntax.Map{}
+	for _, arg := range args {
+		path, value, err := parseOverride(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", arg, err)
+		}
+		node, err := parseValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", arg, err)
+		}
+		root.Entries = append(root.Entries, &syntax.MapEntry{
+			Key:   &encutil.OverrideKey{Arg: arg, Path: path},
+			Value: node,
+		})
+	}
+	return &syntax.Document{Root: root}, nil
+}
+
+func parseOverride(arg string) (path []interface{}, value string, err error) {
+	var (
+		key     strings.Builder
+		haskey  bool
+		escaped bool
+	)
+	endKey := func() error {
+		if !haskey {
+			return fmt.Errorf("empty key in key path")
+		}
+		path = append(path, key.String())
+		key.Reset()
+		haskey = false
+		return nil
+	}
+
+	for i := 0; i < len(arg); i++ {
+		c := arg[i]
+		if escaped {
+			key.WriteByte(c)
+			haskey, escaped = true, false
+			continue
+		}
+		switch c {
+		case '\\':
+			escaped = true
+		case '.':
+			if err := endKey(); err != nil {
+				return nil, "", err
+			}
+		case '[':
+			if haskey {
+				if err := endKey(); err != nil {
+					return nil, "", err
+				}
+			} else if len(path) > 0 && arg[i-1] != ']' {
+				return nil, "", fmt.Errorf("empty key in key path")
+			}
+			end := strings.IndexByte(arg[i:], ']')
+			if end == -1 {
+				return nil, "", fmt.Errorf("unterminated list index")
+			}
+			idx, err := strconv.Atoi(arg[i+1 : i+end])
+			if err != nil || idx < 0 {
+				return nil, "", fmt.Errorf("invalid list index %q", arg[i+1:i+end])
+			}
+			path = append(path, idx)
+			i += end
+			if i+1 < len(arg) && arg[i+1] != '.' && arg[i+1] != '[' && arg[i+1] != '=' {
+				return nil, "", fmt.Errorf("unexpected %q after list index", arg[i+1])
+			}
+			if i+1 < len(arg) && arg[i+1] == '.' {
+				i++
+			}
+		case '=':
+			if haskey || i == 0 || arg[i-1] != ']' {
+				if err := endKey(); err != nil {
+					return nil, "", err
+				}
+			}
+			return path, arg[i+1:], nil
+		default:
+			key.WriteByte(c)
+			haskey = true
+		}
+	}
+	return nil, "", fmt.Errorf("missing '=' in key path assignment")
+}
+
+// Overrides returns a decoder option that applies the values of doc, such as
+// the one that ParseOverrides returns, after the configuration files and the
+// environment. Overrides can be passed several times; their documents are
+// applied in order.
+func Overrides(doc *syntax.Document) DecoderOption {
+	return func(opts *encoding.DecoderOptions) {
+		opts.Overrides = append(opts.Overrides, doc)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"snai.pe/boa/encoding/toml"
+	"snai.pe/boa/encoding/yaml"
+)
+
+func TestOverrides(t *testing.T) {
+	type Server struct {
+		Host string
+		Port int
+	}
+	type Config struct {
+		Name    string
+		Version string
+		Server  Server
+		Servers []Server
+		Labels  map[string]string
+		Extra   map[string]interface{}
+	}
+
+	in := `
+name = "file"
+version = "1.0"
+[server]
+host = "localhost"
+port = 80
+`
+	doc, err := ParseOverrides([]string{
+		"name=cli",
+		"version=1.10",
+		"server.port=8080",
+		"servers[1].host=b",
+		`labels.app\.kubernetes\.io/name=svc`,
+		"extra.debug=true",
+		"extra.ratio=0.5",
+		"extra.tags=a=b",
+	}, yaml.YAML1_2.ResolveScalar)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var config Config
+	err = toml.NewDecoder(strings.NewReader(in)).Option(
+		AutomaticEnv("APP"),
+		Environ([]string{"APP_NAME=env", "APP_SERVER_HOST=example.com"}),
+		Overrides(doc),
+	).Decode(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Config{
+		Name:    "cli",
+		Version: "1.10",
+		Server:  Server{Host: "example.com", Port: 8080},
+		Servers: []Server{{}, {Host: "b"}},
+		Labels:  map[string]string{"app.kubernetes.io/name": "svc"},
+		Extra: map[string]interface{}{
+			"debug": true,
+			"ratio": 0.5,
+			"tags":  "a=b",
+		},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Fatalf("expected %#v, got %#v", expected, config)
+	}
+
+	for _, arg := range []string{"name", "=value", "a..b=1", "a.=1", "a[x]=1", "a[0=1", "a[0]b=1"} {
+		if _, err := ParseOverrides([]string{arg}, nil); err == nil || !strings.HasPrefix(err.Error(), arg+": ") {
+			t.Errorf("%q: expected an error naming the argument, got %v", arg, err)
+		}
+	}
+
+	doc, err = ParseOverrides([]string{"server.port=http"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = toml.NewDecoder(strings.NewReader(in)).Option(Overrides(doc)).Decode(&config)
+	if err == nil || !strings.HasPrefix(err.Error(), "server.port=http: <value>.server.port: ") {
+		t.Fatalf("expected an error naming the argument and path, got %v", err)
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"snai.pe/boa/encoding"
+	"snai.pe/boa/encoding/json5"
+	"snai.pe/boa/encoding/toml"
+	"snai.pe/boa/encoding/yaml"
+)
+
+type templateServer struct {
+	Host    string         `help:"Address to listen on"`
+	Timeout *time.Duration `help:"Request timeout"`
+}
+
+type templateConfig struct {
+	Name   string `help:"Name of the application"`
+	Debug  bool   `env:"DEBUG"`
+	Tags   []string
+	Server templateServer
+	Proxy  *templateServer `help:"Proxy to forward requests to"`
+}
+
+func TestTemplate(t *testing.T) {
+	defaults := templateConfig{
+		Name:   "app",
+		Server: templateServer{Host: "localhost"},
+	}
+
+	tcases := []struct {
+		name       string
+		newEncoder func(io.Writer) encoding.Encoder
+		newDecoder func(io.Reader) encoding.Decoder
+		expected   string
+	}{
+		{
+			name:       "toml",
+			newEncoder: toml.NewEncoder,
+			newDecoder: toml.NewDecoder,
+			expected: `# Name of the application
+# Type: string
+# Default: "app"
+# Environment: MYAPP_NAME
+name = "app"
+# Type: boolean
+# Default: false
+# Environment: DEBUG
+debug = false
+# Type: list of string
+# Environment: MYAPP_TAGS
+# tags = []
+
+# Type: section
+[server]
+# Address to listen on
+# Type: string
+# Default: "localhost"
+# Environment: MYAPP_SERVER_HOST
+host = "localhost"
+# Request timeout
+# Type: duration
+# Environment: MYAPP_SERVER_TIMEOUT
+# timeout = 0
+
+# Proxy to forward requests to
+# Type: section
+# [proxy]
+# Address to listen on
+# Type: string
+# Default: ""
+# Environment: MYAPP_PROXY_HOST
+# host = ""
+# Request timeout
+# Type: duration
+# Environment: MYAPP_PROXY_TIMEOUT
+# timeout = 0
+`,
+		},
+		{
+			name:       "json5",
+			newEncoder: json5.NewEncoder,
+			newDecoder: json5.NewDecoder,
+			expected: `{
+  // Name of the application
+  // Type: string
+  // Default: "app"
+  // Environment: MYAPP_NAME
+  name: "app",
+  // Type: boolean
+  // Default: false
+  // Environment: DEBUG
+  debug: false,
+  // Type: list of string
+  // Environment: MYAPP_TAGS
+  // tags: [],
+  // Type: section
+  server: {
+    // Address to listen on
+    // Type: string
+    // Default: "localhost"
+    // Environment: MYAPP_SERVER_HOST
+    host: "localhost",
+    // Request timeout
+    // Type: duration
+    // Environment: MYAPP_SERVER_TIMEOUT
+    // timeout: 0,
+  },
+  // Proxy to forward requests to
+  // Type: section
+  // proxy: {
+    // Address to listen on
+    // Type: string
+    // Default: ""
+    // Environment: MYAPP_PROXY_HOST
+    // host: "",
+    // Request timeout
+    // Type: duration
+    // Environment: MYAPP_PROXY_TIMEOUT
+    // timeout: 0,
+  // },
+}
+`,
+		},
+		{
+			name:       "yaml",
+			newEncoder: yaml.NewEncoder,
+			newDecoder: yaml.NewDecoder,
+			expected: `# Name of the application
+# Type: string
+# Default: "app"
+# Environment: MYAPP_NAME
+name: app
+# Type: boolean
+# Default: false
+# Environment: DEBUG
+debug: false
+# Type: list of string
+# Environment: MYAPP_TAGS
+# tags: []
+# Type: section
+server:
+  # Address to listen on
+  # Type: string
+  # Default: "localhost"
+  # Environment: MYAPP_SERVER_HOST
+  host: localhost
+  # Request timeout
+  # Type: duration
+  # Environment: MYAPP_SERVER_TIMEOUT
+  # timeout: 0
+# Proxy to forward requests to
+# Type: section
+# proxy:
+  # Address to listen on
+  # Type: string
+  # Default: ""
+  # Environment: MYAPP_PROXY_HOST
+  # host: ""
+  # Request timeout
+  # Type: duration
+  # Environment: MYAPP_PROXY_TIMEOUT
+  # timeout: 0
+`,
+		},
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := tcase.newEncoder(&out).Option(Template("MYAPP")).Encode(&defaults); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tcase.expected {
+				t.Errorf("unexpected template:\n%s\nexpected:\n%s", out.String(), tcase.expected)
+			}
+
+			// The template must load back into the defaults.
+			var actual templateConfig
+			if err := tcase.newDecoder(&out).Decode(&actual); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, defaults) {
+				t.Errorf("template loaded as %+v, expected %+v", actual, defaults)
+			}
+		})
+	}
+}
+
+type templateNode struct {
+	Name string
+	Next *templateNode
+}
+
+func TestTemplateRecursive(t *testing.T) {
+	for _, newEncoder := range []func(io.Writer) encoding.Encoder{toml.NewEncoder, json5.NewEncoder, yaml.NewEncoder} {
+		var out bytes.Buffer
+		if err := newEncoder(&out).Option(Template("")).Encode(&templateNode{Name: "head"}); err != nil {
+			t.Fatal(err)
+		}
+		// The nil Next field is expanded once, and its own Next field is
+		// left out rather than expanded again.
+		if n := bytes.Count(out.Bytes(), []byte("name")); n != 2 {
+			t.Errorf("expected the recursive type to be expanded once, got %d names:\n%s", n, out.String())
+		}
+	}
+}
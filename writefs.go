@@ -0,0 +1,387 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WritableFS is a file system in which configuration files can be saved.
+//
+// Names follow the same rules as the ones of fs.FS.
+type WritableFS interface {
+	fs.FS
+
+	// Create creates or truncates the named file. Its contents may only be
+	// visible once it is closed.
+	Create(name string) (io.WriteCloser, error)
+
+	// Rename renames (moves) the oldname file to newname, replacing it if it
+	// already exists.
+	Rename(oldname, newname string) error
+
+	// Remove removes the named file or empty directory.
+	Remove(name string) error
+
+	// MkdirAll creates the named directory, along with any necessary
+	// parents. It does nothing if the directory already exists.
+	MkdirAll(name string, perm fs.FileMode) error
+}
+
+// NewDirFS returns a WritableFS for the tree of files rooted at the directory
+// dir, in the same way as os.DirFS does.
+//
+// Save replaces the files of the returned FS atomically, preserving their
+// mode and ownership, as described in Save.
+func NewDirFS(dir string) WritableFS {
+	return dirFS{FS: os.DirFS(dir), Dir: dir}
+}
+
+type dirFS struct {
+	fs.FS
+	Dir string
+}
+
+func (f dirFS) join(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return filepath.Join(f.Dir, filepath.FromSlash(name)), nil
+}
+
+func (f dirFS) Create(name string) (io.WriteCloser, error) {
+	path, err := f.join("create", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Create(path)
+}
+
+func (f dirFS) Rename(oldname, newname string) error {
+	oldpath, err := f.join("rename", oldname)
+	if err != nil {
+		return err
+	}
+	newpath, err := f.join("rename", newname)
+	if err != nil {
+		return err
+	}
+	return os.Rename(oldpath, newpath)
+}
+
+func (f dirFS) Remove(name string) error {
+	path, err := f.join("remove", name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func (f dirFS) MkdirAll(name string, perm fs.FileMode) error {
+	path, err := f.join("mkdir", name)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(path, perm)
+}
+
+// MemFS is an in-memory WritableFS. It is primarily useful to test how
+// programs load and save their configuration, with SetConfigHomeFS or
+// Loader.ConfigHome, without touching the user's files.
+//
+// A MemFS must be created with NewMemFS, and is safe for concurrent use.
+type MemFS struct {
+	mu    sync.Mutex
+	files map[string]*memEntry
+}
+
+// memEntry is a file or directory of a MemFS. Entries are never modified once
+// stored, so that opened files can share their contents. The parent
+// directories of entries exist implicitly.
+type memEntry struct {
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+// NewMemFS returns a new MemFS, containing the specified files, by name.
+// The contents of the files are copied, and files may be nil.
+func NewMemFS(files map[string][]byte) *MemFS {
+	m := &MemFS{files: make(map[string]*memEntry, len(files))}
+	now := time.Now()
+	for name, data := range files {
+		m.files[name] = &memEntry{data: append([]byte(nil), data...), mode: 0666, modTime: now}
+	}
+	return m
+}
+
+// Open opens the named file. Changes made to the MemFS after the file is
+// opened are not visible in the opened file.
+func (m *MemFS) Open(name string) (fs.File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.open("open", name)
+}
+
+// open opens the named file or directory. m.mu must be held.
+func (m *MemFS) open(op, name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	entry, ok := m.files[name]
+	if ok && !entry.mode.IsDir() {
+		return newBytesFile(name, entry.data, entry.mode, entry.modTime), nil
+	}
+
+	prefix := name + "/"
+	if name == "." {
+		prefix = ""
+	}
+	children := map[string]fs.FileInfo{}
+	for fname, fentry := range m.files {
+		if !strings.HasPrefix(fname, prefix) || fname == name {
+			continue
+		}
+		child := fname[len(prefix):]
+		if i := strings.IndexByte(child, '/'); i >= 0 {
+			child = child[:i]
+			if _, ok := children[child]; !ok {
+				children[child] = bytesFileInfo{name: child, mode: fs.ModeDir | 0777}
+			}
+			continue
+		}
+		children[child] = bytesFileInfo{name: child, size: int64(len(fentry.data)), mode: fentry.mode, modTime: fentry.modTime}
+	}
+	if !ok && len(children) == 0 && name != "." {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+
+	dir := &memDir{info: bytesFileInfo{name: path.Base(name), mode: fs.ModeDir | 0777}}
+	if ok {
+		dir.info.mode = entry.mode
+		dir.info.modTime = entry.modTime
+	}
+	for _, info := range children {
+		dir.entries = append(dir.entries, fs.FileInfoToDirEntry(info))
+	}
+	sort.Slice(dir.entries, func(i, j int) bool { return dir.entries[i].Name() < dir.entries[j].Name() })
+	return dir, nil
+}
+
+// stat returns the file information of the named file. m.mu must be held.
+func (m *MemFS) stat(op, name string) (fs.FileInfo, error) {
+	f, err := m.open(op, name)
+	if err != nil {
+		return nil, err
+	}
+	return f.Stat()
+}
+
+func (m *MemFS) Create(name string) (io.WriteCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if info, err := m.stat("create", path.Dir(name)); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: fs.ErrNotExist}
+	}
+	if info, err := m.stat("create", name); err == nil && info.IsDir() {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: fs.ErrExist}
+	}
+	m.files[name] = &memEntry{mode: 0666, modTime: time.Now()}
+	return &memFile{fs: m, name: name}, nil
+}
+
+func (m *MemFS) Rename(oldname, newname string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.stat("rename", newname); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if info, err := m.stat("rename", path.Dir(newname)); err != nil {
+		return err
+	} else if !info.IsDir() {
+		return &fs.PathError{Op: "rename", Path: newname, Err: fs.ErrNotExist}
+	}
+	file, ok := m.files[oldname]
+	if !ok || file.mode.IsDir() {
+		// Only files can be renamed.
+		return &fs.PathError{Op: "rename", Path: oldname, Err: fs.ErrNotExist}
+	}
+	if info, err := m.stat("rename", newname); err == nil && info.IsDir() {
+		return &fs.PathError{Op: "rename", Path: newname, Err: fs.ErrExist}
+	}
+	delete(m.files, oldname)
+	m.files[newname] = file
+	return nil
+}
+
+func (m *MemFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := m.open("remove", name)
+	if err != nil {
+		return err
+	}
+	if dir, ok := f.(*memDir); ok && len(dir.entries) > 0 {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrExist}
+	}
+	delete(m.files, name)
+	return nil
+}
+
+func (m *MemFS) MkdirAll(name string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrInvalid}
+	}
+	var dirs []string
+	for dir := name; dir != "."; dir = path.Dir(dir) {
+		dirs = append(dirs, dir)
+	}
+	// Create the parents first, so that none of them is a file.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		info, err := m.stat("mkdir", dirs[i])
+		switch {
+		case err == nil && !info.IsDir():
+			return &fs.PathError{Op: "mkdir", Path: dirs[i], Err: fs.ErrExist}
+		case err == nil:
+			continue
+		}
+		m.files[dirs[i]] = &memEntry{mode: fs.ModeDir | perm.Perm(), modTime: time.Now()}
+	}
+	return nil
+}
+
+// memDir is an opened directory of a MemFS.
+type memDir struct {
+	info    bytesFileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *memDir) Close() error               { return nil }
+
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: errors.New("is a directory")}
+}
+
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	entries := d.entries[d.offset:]
+	if n > 0 && len(entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > 0 && len(entries) > n {
+		entries = entries[:n]
+	}
+	d.offset += len(entries)
+	return append([]fs.DirEntry(nil), entries...), nil
+}
+
+// memFile is a file of a MemFS that is being written. Its contents are
+// stored in the MemFS when it is closed.
+type memFile struct {
+	fs     *MemFS
+	name   string
+	buf    bytes.Buffer
+	closed bool
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "write", Path: f.name, Err: fs.ErrClosed}
+	}
+	return f.buf.Write(p)
+}
+
+func (f *memFile) Close() error {
+	if f.closed {
+		return &fs.PathError{Op: "close", Path: f.name, Err: fs.ErrClosed}
+	}
+	f.closed = true
+
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if _, ok := f.fs.files[f.name]; !ok {
+		// The file was removed or renamed while it was being written.
+		return nil
+	}
+	f.fs.files[f.name] = &memEntry{data: f.buf.Bytes(), mode: 0666, modTime: time.Now()}
+	return nil
+}
+
+// writeFS replaces the named file of fsys with the contents written by write,
+// keeping the specified number of backups, in the same way as Save does.
+func writeFS(fsys WritableFS, name string, backups int, write func(io.Writer) error) error {
+	if err := fsys.MkdirAll(path.Dir(name), 0777); err != nil {
+		return err
+	}
+	tmp := path.Join(path.Dir(name), "."+path.Base(name)+".tmp")
+	f, err := fsys.Create(tmp)
+	if err != nil {
+		return err
+	}
+	err = write(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fsys.Remove(tmp)
+		return err
+	}
+
+	if _, err := fs.Stat(fsys, name); err == nil && backups > 0 {
+		if err := backupFS(fsys, name, backups); err != nil {
+			fsys.Remove(tmp)
+			return err
+		}
+	}
+	return fsys.Rename(tmp, name)
+}
+
+func backupFS(fsys WritableFS, name string, backups int) error {
+	backup := name + "~"
+	if backups > 1 {
+		for i := backups - 1; i > 0; i-- {
+			err := fsys.Rename(fmt.Sprintf("%s.%d", name, i), fmt.Sprintf("%s.%d", name, i+1))
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+		backup = name + ".1"
+	}
+
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+	f, err := fsys.Create(backup)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Franklin "Snaipe" Mathieu.
+//
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package boa
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMemFS(t *testing.T) {
+	m := NewMemFS(map[string][]byte{
+		"myapp.toml": []byte("name = \"old\"\n"),
+	})
+
+	if err := m.MkdirAll("myapp.toml/sub", 0777); !errors.Is(err, fs.ErrExist) {
+		t.Fatalf("expected %v, got %v", fs.ErrExist, err)
+	}
+	if _, err := m.Create("missing/file.toml"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected %v, got %v", fs.ErrNotExist, err)
+	}
+	if err := m.MkdirAll("a/b", 0777); err != nil {
+		t.Fatal(err)
+	}
+	f, err := m.Create("a/b/file.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("x = 1\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Rename("a/b/file.toml", "a/file.toml"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Remove("a/b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Remove("a"); !errors.Is(err, fs.ErrExist) {
+		t.Fatalf("expected removing a non-empty directory to fail, got %v", err)
+	}
+	if err := fstest.TestFS(m, "myapp.toml", "a/file.toml"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSaveWritableFS(t *testing.T) {
+	type Config struct {
+		Name string
+		Port int
+	}
+
+	m := NewMemFS(map[string][]byte{
+		"myapp.toml": []byte("name = \"old\"\n"),
+	})
+	SetConfigHomeFS(m)
+	SetOptions(Backups(1))
+	defer func() { SetConfigHomeFS(nil); defaultEncoderOptions = nil }()
+
+	if err := Save("myapp.toml", Config{Name: "new", Port: 8080}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Save("sub/other.json5", Config{Name: "other"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var config Config
+	f := Open("myapp", m)
+	defer f.Close()
+	if err := NewDecoder(f).Decode(&config); err != nil {
+		t.Fatal(err)
+	}
+	if config != (Config{Name: "new", Port: 8080}) {
+		t.Fatalf("unexpected config %+v", config)
+	}
+
+	backup, err := fs.ReadFile(m, "myapp.toml~")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(backup) != "name = \"old\"\n" {
+		t.Fatalf("unexpected backup %q", backup)
+	}
+	if err := fstest.TestFS(m, "myapp.toml", "myapp.toml~", "sub/other.json5"); err != nil {
+		t.Fatal(err)
+	}
+}